@@ -5,30 +5,43 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
 	"syscall"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	ocmsdk "github.com/openshift-online/ocm-sdk-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 
 	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/tracing"
 )
 
 var (
-	argLocation           string
-	argCosmosName         string
-	argCosmosURL          string
-	argClustersServiceURL string
-	argInsecure           bool
+	argLocation             string
+	argCosmosName           string
+	argCosmosURL            string
+	argClustersServiceURL   string
+	argInsecure             bool
+	argMetricsListenAddress string
+	argPprofListenAddress   string
+	argOnce                 bool
+	argOperationTTL         time.Duration
+	argTracingEndpoint      string
 
 	processName = filepath.Base(os.Args[0])
 
@@ -59,6 +72,11 @@ func init() {
 	rootCmd.Flags().StringVar(&argCosmosURL, "cosmos-url", os.Getenv("DB_URL"), "Cosmos database URL")
 	rootCmd.Flags().StringVar(&argClustersServiceURL, "clusters-service-url", "https://api.openshift.com", "URL of the OCM API gateway")
 	rootCmd.Flags().BoolVar(&argInsecure, "insecure", false, "Skip validating TLS for clusters-service")
+	rootCmd.Flags().BoolVar(&argOnce, "once", false, "Poll active operations once, then exit with an error if polling any of them failed")
+	rootCmd.Flags().DurationVar(&argOperationTTL, "operation-ttl", database.DefaultOperationTimeToLive, "How long to keep operation documents after the operation finishes (0 defers to the container default)")
+	rootCmd.Flags().StringVar(&argMetricsListenAddress, "metrics-listen-address", ":8081", "Address on which to serve Prometheus metrics (empty disables)")
+	rootCmd.Flags().StringVar(&argPprofListenAddress, "pprof-listen-address", "", "Address on which to serve pprof profiling endpoints (empty disables)")
+	rootCmd.Flags().StringVar(&argTracingEndpoint, "tracing-endpoint", os.Getenv(tracing.EndpointEnvVar), "URL of an OTLP/HTTP collector to export operation traces to (empty disables tracing)")
 
 	rootCmd.MarkFlagsRequiredTogether("cosmos-name", "cosmos-url")
 
@@ -99,13 +117,46 @@ func newCosmosDBClient() (database.DBClient, error) {
 		return nil, err
 	}
 
-	return database.NewCosmosDBClient(context.Background(), databaseClient)
+	return database.NewCosmosDBClient(context.Background(), databaseClient, argOperationTTL, prometheus.DefaultRegisterer)
+}
+
+// newMetricsServer returns a server for the Prometheus metrics endpoint,
+// which exposes the metrics registered with prometheus.DefaultRegisterer.
+func newMetricsServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+
+	return &http.Server{Handler: mux}
+}
+
+// newPprofServer returns a server for the net/http/pprof endpoints. It uses
+// its own mux rather than http.DefaultServeMux so the endpoints are never
+// exposed by any other listener.
+func newPprofServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return &http.Server{Handler: mux}
 }
 
 func Run(cmd *cobra.Command, args []string) error {
 	handler := slog.NewJSONHandler(os.Stdout, nil)
 	logger := slog.New(handler)
 
+	shutdownTracing, err := tracing.Setup(context.Background(), argTracingEndpoint, "aro-hcp-backend")
+	if err != nil {
+		return fmt.Errorf("Failed to set up tracing: %w", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("tracing shutdown failed: %v", err))
+		}
+	}()
+
 	// Create database client
 	dbClient, err := newCosmosDBClient()
 	if err != nil {
@@ -113,9 +164,12 @@ func Run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create OCM connection
+	// Requests are retried by ocm.ClusterServiceClient, which knows
+	// which requests are safe to retry, so disable the SDK's retries.
 	ocmConnection, err := ocmsdk.NewUnauthenticatedConnectionBuilder().
 		URL(argClustersServiceURL).
 		Insecure(argInsecure).
+		RetryLimit(0).
 		Build()
 	if err != nil {
 		return fmt.Errorf("Failed to create OCM connection: %w", err)
@@ -123,6 +177,42 @@ func Run(cmd *cobra.Command, args []string) error {
 
 	logger.Info(fmt.Sprintf("%s (%s) started", cmd.Short, cmd.Version))
 
+	if argOnce {
+		return NewOperationsScanner(dbClient, ocmConnection).RunOnce(context.Background(), logger)
+	}
+
+	var pprofServer *http.Server
+	if argPprofListenAddress != "" {
+		pprofListener, err := net.Listen("tcp", argPprofListenAddress)
+		if err != nil {
+			return fmt.Errorf("Failed to listen for pprof: %w", err)
+		}
+
+		pprofServer = newPprofServer()
+		go func() {
+			logger.Info(fmt.Sprintf("pprof listening on %s", pprofListener.Addr()))
+			if err := pprofServer.Serve(pprofListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(fmt.Sprintf("pprof server failed: %v", err))
+			}
+		}()
+	}
+
+	var metricsServer *http.Server
+	if argMetricsListenAddress != "" {
+		metricsListener, err := net.Listen("tcp", argMetricsListenAddress)
+		if err != nil {
+			return fmt.Errorf("Failed to listen for metrics: %w", err)
+		}
+
+		metricsServer = newMetricsServer()
+		go func() {
+			logger.Info(fmt.Sprintf("metrics listening on %s", metricsListener.Addr()))
+			if err := metricsServer.Serve(metricsListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(fmt.Sprintf("metrics server failed: %v", err))
+			}
+		}()
+	}
+
 	operationsScanner := NewOperationsScanner(dbClient, ocmConnection)
 
 	stop := make(chan struct{})
@@ -137,6 +227,18 @@ func Run(cmd *cobra.Command, args []string) error {
 
 	operationsScanner.Join()
 
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("metrics server shutdown failed: %v", err))
+		}
+	}
+
+	if pprofServer != nil {
+		if err := pprofServer.Shutdown(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("pprof server shutdown failed: %v", err))
+		}
+	}
+
 	logger.Info(fmt.Sprintf("%s (%s) stopped", cmd.Short, cmd.Version))
 
 	return nil
@@ -13,13 +13,17 @@ import (
 	"os"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
 	ocmsdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/Azure/ARO-HCP/internal/api/arm"
 	"github.com/Azure/ARO-HCP/internal/database"
 	"github.com/Azure/ARO-HCP/internal/ocm"
+	"github.com/Azure/ARO-HCP/internal/tracing"
 )
 
 const (
@@ -27,9 +31,28 @@ const (
 	defaultClusterServicePollInterval   = 10 * time.Second
 )
 
+// activeOperationsFilter matches operations that have not reached a terminal state.
+var activeOperationsFilter = &database.OperationDocumentFilter{
+	Statuses: []arm.ProvisioningState{
+		arm.ProvisioningStateAccepted,
+		arm.ProvisioningStateDeleting,
+		arm.ProvisioningStateProvisioning,
+		arm.ProvisioningStateUpdating,
+	},
+}
+
+// subscriptionLockClient is the subset of database.LockClient used to
+// serialize updates within a subscription.
+type subscriptionLockClient interface {
+	GetDefaultTimeToLive() time.Duration
+	AcquireLock(ctx context.Context, id string, timeout *time.Duration) (*azcosmos.ItemResponse, error)
+	HoldLock(ctx context.Context, item *azcosmos.ItemResponse) (context.Context, database.StopHoldLock)
+	ReleaseLock(ctx context.Context, item *azcosmos.ItemResponse) error
+}
+
 type OperationsScanner struct {
 	dbClient           database.DBClient
-	lockClient         *database.LockClient
+	lockClient         subscriptionLockClient
 	clusterService     ocm.ClusterServiceClient
 	activeOperations   []*database.OperationDocument
 	notificationClient *http.Client
@@ -74,13 +97,14 @@ func (s *OperationsScanner) Run(logger *slog.Logger, stop <-chan struct{}) {
 
 	ctx := context.Background()
 
-	// Poll database immediately on startup.
-	s.pollDBOperations(ctx, logger)
+	// Poll database immediately on startup. Errors are logged
+	// and polling is retried on the next tick.
+	_ = s.pollDBOperations(ctx, logger)
 
 	for {
 		select {
 		case <-pollDBOperationsTicker.C:
-			s.pollDBOperations(ctx, logger)
+			_ = s.pollDBOperations(ctx, logger)
 		case <-pollCSOperationsTicker.C:
 			s.pollCSOperations(ctx, logger, stop)
 		case <-stop:
@@ -93,10 +117,32 @@ func (s *OperationsScanner) Join() {
 	<-s.done
 }
 
-func (s *OperationsScanner) pollDBOperations(ctx context.Context, logger *slog.Logger) {
+// RunOnce makes a single pass over the active operations, polling each once,
+// and logs a summary. It returns an error if the active operations could not
+// be loaded or if polling any of them failed.
+func (s *OperationsScanner) RunOnce(ctx context.Context, logger *slog.Logger) error {
+	err := s.pollDBOperations(ctx, logger)
+	if err != nil {
+		return err
+	}
+
+	polled := len(s.activeOperations)
+	failed := s.pollCSOperations(ctx, logger, nil)
+
+	logger.Info(fmt.Sprintf("Polled %d active operations: %d still active, %d failed", polled, len(s.activeOperations), failed))
+
+	if failed > 0 {
+		return fmt.Errorf("Failed to poll %d of %d active operations", failed, polled)
+	}
+	return nil
+}
+
+// pollDBOperations refreshes the list of active operations from the database.
+// On error, the previous list of active operations is kept.
+func (s *OperationsScanner) pollDBOperations(ctx context.Context, logger *slog.Logger) error {
 	var activeOperations []*database.OperationDocument
 
-	iterator := s.dbClient.ListAllOperationDocs(ctx)
+	iterator := s.dbClient.ListOperationDocs(ctx, activeOperationsFilter)
 
 	for item := range iterator.Items(ctx) {
 		var doc *database.OperationDocument
@@ -107,9 +153,7 @@ func (s *OperationsScanner) pollDBOperations(ctx context.Context, logger *slog.L
 			continue
 		}
 
-		if !doc.Status.IsTerminal() {
-			activeOperations = append(activeOperations, doc)
-		}
+		activeOperations = append(activeOperations, doc)
 	}
 
 	err := iterator.GetError()
@@ -121,10 +165,15 @@ func (s *OperationsScanner) pollDBOperations(ctx context.Context, logger *slog.L
 	} else {
 		logger.Error(fmt.Sprintf("Error while paging through Cosmos query results: %s", err.Error()))
 	}
+
+	return err
 }
 
-func (s *OperationsScanner) pollCSOperations(ctx context.Context, logger *slog.Logger, stop <-chan struct{}) {
+// pollCSOperations polls Cluster Service for the status of each active
+// operation and returns the number of operations that could not be polled.
+func (s *OperationsScanner) pollCSOperations(ctx context.Context, logger *slog.Logger, stop <-chan struct{}) int {
 	var activeOperations []*database.OperationDocument
+	var failed int
 
 	for _, doc := range s.activeOperations {
 		select {
@@ -134,34 +183,71 @@ func (s *OperationsScanner) pollCSOperations(ctx context.Context, logger *slog.L
 			var requeue bool
 			var err error
 
-			opLogger := logger.With(
-				"operation", doc.Request,
-				"operation_id", doc.ID,
-				"resource_id", doc.ExternalID.String(),
-				"internal_id", doc.InternalID.String())
-
-			switch doc.InternalID.Kind() {
-			case cmv1.ClusterKind:
-				requeue, err = s.pollClusterOperation(ctx, opLogger, doc)
-			case cmv1.NodePoolKind:
-				requeue, err = s.pollNodePoolOperation(ctx, opLogger, doc)
-			}
+			opLogger := operationLogger(logger, doc)
+
+			requeue, err = s.pollOperation(ctx, opLogger, doc)
 			if requeue {
 				activeOperations = append(activeOperations, doc)
 			}
 			if err != nil {
 				opLogger.Error(fmt.Sprintf("Error while polling operation '%s': %s", doc.ID, err.Error()))
+				failed++
 			}
 		}
 	}
 
 	s.activeOperations = activeOperations
+
+	return failed
+}
+
+// pollOperation updates one operation from Cluster Service within a span
+// covering all the work done for it, and returns whether the operation
+// is still active.
+func (s *OperationsScanner) pollOperation(ctx context.Context, logger *slog.Logger, doc *database.OperationDocument) (bool, error) {
+	var requeue bool
+	var err error
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "poll "+string(doc.Request),
+		trace.WithAttributes(operationSpanAttributes(doc)...))
+	defer func() { tracing.EndSpan(span, err) }()
+
+	ctx = database.ContextWithLogger(ctx, logger)
+
+	switch doc.InternalID.Kind() {
+	case cmv1.ClusterKind:
+		requeue, err = s.pollClusterOperation(ctx, logger, doc)
+	case cmv1.NodePoolKind:
+		requeue, err = s.pollNodePoolOperation(ctx, logger, doc)
+	}
+
+	return requeue, err
+}
+
+// operationLogger returns a logger that tags every entry with the identity
+// of the operation, its resource, and the request that started it.
+func operationLogger(logger *slog.Logger, doc *database.OperationDocument) *slog.Logger {
+	attrs := []any{
+		"operation", doc.Request,
+		"operation_id", doc.ID,
+		"resource_id", doc.ExternalID.String(),
+		"internal_id", doc.InternalID.String(),
+	}
+	if doc.ClientRequestID != "" {
+		attrs = append(attrs, "client_request_id", doc.ClientRequestID)
+	}
+	if doc.CorrelationRequestID != "" {
+		attrs = append(attrs, "correlation_request_id", doc.CorrelationRequestID)
+	}
+	return logger.With(attrs...)
 }
 
 func (s *OperationsScanner) pollClusterOperation(ctx context.Context, logger *slog.Logger, doc *database.OperationDocument) (bool, error) {
 	var requeue bool = true
 
-	clusterStatus, err := s.clusterService.GetCSClusterStatus(ctx, doc.InternalID)
+	csCtx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.GetCSClusterStatus")
+	clusterStatus, err := s.clusterService.GetCSClusterStatus(csCtx, doc.InternalID)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		var ocmError *ocmerrors.Error
 		if errors.As(err, &ocmError) && ocmError.Status() == http.StatusNotFound && doc.Request == database.OperationRequestDelete {
@@ -180,8 +266,12 @@ func (s *OperationsScanner) pollClusterOperation(ctx context.Context, logger *sl
 		logger.Warn(err.Error())
 		err = nil
 	} else {
+		var percentComplete float64
+		if doc.Request == database.OperationRequestCreate {
+			percentComplete = clusterCreatePercentComplete(clusterStatus)
+		}
 		err = s.withSubscriptionLock(ctx, logger, doc.ExternalID.SubscriptionID, func(ctx context.Context) error {
-			return s.updateOperationStatus(ctx, logger, doc, opStatus, opError)
+			return s.updateOperationStatus(ctx, logger, doc, opStatus, opError, percentComplete)
 		})
 	}
 
@@ -218,40 +308,64 @@ func (s *OperationsScanner) withSubscriptionLock(ctx context.Context, logger *sl
 }
 
 func (s *OperationsScanner) deleteOperationCompleted(ctx context.Context, logger *slog.Logger, doc *database.OperationDocument) error {
-	err := s.dbClient.DeleteResourceDoc(ctx, doc.ExternalID)
+	dbCtx, span := tracing.StartSpan(ctx, tracerName, "database.DeleteResourceDoc")
+	err := s.dbClient.DeleteResourceDoc(dbCtx, doc.ExternalID)
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
 
 	// Save a final "succeeded" operation status until TTL expires.
 	const opStatus arm.ProvisioningState = arm.ProvisioningStateSucceeded
-	updated, err := s.dbClient.UpdateOperationDoc(ctx, doc.ID, func(updateDoc *database.OperationDocument) bool {
+	var previousStatus arm.ProvisioningState
+	dbCtx, span = tracing.StartSpan(ctx, tracerName, "database.UpdateOperationDoc")
+	updated, err := s.dbClient.UpdateOperationDoc(dbCtx, doc.ID, func(updateDoc *database.OperationDocument) bool {
+		previousStatus = updateDoc.Status
 		return updateDoc.UpdateStatus(opStatus, nil)
 	})
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
 	if updated {
-		logger.Info(fmt.Sprintf("Updated Operations container item for '%s' with status '%s'", doc.ID, opStatus))
+		logger.Info(fmt.Sprintf("Updated Operations container item for '%s' with status '%s'", doc.ID, opStatus),
+			"previous_status", previousStatus, "status", opStatus)
 		s.maybePostAsyncNotification(ctx, logger, doc)
 	}
 
 	return nil
 }
 
-func (s *OperationsScanner) updateOperationStatus(ctx context.Context, logger *slog.Logger, doc *database.OperationDocument, opStatus arm.ProvisioningState, opError *arm.CloudErrorBody) error {
-	updated, err := s.dbClient.UpdateOperationDoc(ctx, doc.ID, func(updateDoc *database.OperationDocument) bool {
-		return updateDoc.UpdateStatus(opStatus, opError)
+func (s *OperationsScanner) updateOperationStatus(ctx context.Context, logger *slog.Logger, doc *database.OperationDocument, opStatus arm.ProvisioningState, opError *arm.CloudErrorBody, percentComplete float64) error {
+	var statusUpdated bool
+
+	var previousStatus arm.ProvisioningState
+
+	dbCtx, span := tracing.StartSpan(ctx, tracerName, "database.UpdateOperationDoc")
+	updated, err := s.dbClient.UpdateOperationDoc(dbCtx, doc.ID, func(updateDoc *database.OperationDocument) bool {
+		// An operation canceled through the frontend stays canceled
+		// even if Cluster Service goes on to finish the work.
+		if updateDoc.Status == arm.ProvisioningStateCanceled {
+			return false
+		}
+		previousStatus = updateDoc.Status
+		statusUpdated = updateDoc.UpdateStatus(opStatus, opError)
+		progressUpdated := updateDoc.UpdatePercentComplete(percentComplete)
+		return statusUpdated || progressUpdated
 	})
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
-	if updated {
-		logger.Info(fmt.Sprintf("Updated Operations container item for '%s' with status '%s'", doc.ID, opStatus))
+	// Progress alone is not worth an async notification.
+	if updated && statusUpdated {
+		logger.Info(fmt.Sprintf("Updated Operations container item for '%s' with status '%s'", doc.ID, opStatus),
+			"previous_status", previousStatus, "status", opStatus)
 		s.maybePostAsyncNotification(ctx, logger, doc)
 	}
 
-	updated, err = s.dbClient.UpdateResourceDoc(ctx, doc.ExternalID, func(updateDoc *database.ResourceDocument) bool {
+	dbCtx, span = tracing.StartSpan(ctx, tracerName, "database.UpdateResourceDoc")
+	updated, err = s.dbClient.UpdateResourceDoc(dbCtx, doc.ExternalID, func(updateDoc *database.ResourceDocument) bool {
 		var updated bool
 
 		if doc.ID == updateDoc.ActiveOperationID {
@@ -267,6 +381,7 @@ func (s *OperationsScanner) updateOperationStatus(ctx context.Context, logger *s
 
 		return updated
 	})
+	tracing.EndSpan(span, err)
 	if err != nil {
 		return err
 	}
@@ -307,6 +422,30 @@ func (s *OperationsScanner) postAsyncNotification(ctx context.Context, url strin
 	return nil
 }
 
+// clusterCreatePercentComplete returns a coarse estimate of how far along a
+// cluster creation is, or zero if no estimate can be made from the status.
+func clusterCreatePercentComplete(clusterStatus *cmv1.ClusterStatus) float64 {
+	// FIXME Cluster Service does not report installation progress, so
+	//       this is derived from the cluster state and readiness flags.
+	switch clusterStatus.State() {
+	case cmv1.ClusterStateValidating:
+		return 10
+	case cmv1.ClusterStateInstalling:
+		percentComplete := 25.0
+		if clusterStatus.DNSReady() {
+			percentComplete += 25
+		}
+		if clusterStatus.OIDCReady() {
+			percentComplete += 25
+		}
+		return percentComplete
+	case cmv1.ClusterStateReady:
+		return 100
+	default:
+		return 0
+	}
+}
+
 func convertClusterStatus(clusterStatus *cmv1.ClusterStatus, current arm.ProvisioningState) (arm.ProvisioningState, *arm.CloudErrorBody, error) {
 	var opStatus arm.ProvisioningState = current
 	var opError *arm.CloudErrorBody
@@ -4,14 +4,24 @@ package main
 // Licensed under the Apache License 2.0.
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	ocmsdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/Azure/ARO-HCP/internal/api/arm"
 	"github.com/Azure/ARO-HCP/internal/database"
@@ -133,9 +143,11 @@ func TestUpdateOperationStatus(t *testing.T) {
 		resourceDocPresent               bool
 		resourceMatchOperationID         bool
 		resourceProvisioningState        arm.ProvisioningState
+		percentComplete                  float64
 		expectAsyncNotification          bool
 		expectResourceOperationIDCleared bool
 		expectResourceProvisioningState  arm.ProvisioningState
+		expectPercentComplete            float64
 		expectError                      bool
 	}{
 		{
@@ -162,6 +174,18 @@ func TestUpdateOperationStatus(t *testing.T) {
 			expectResourceProvisioningState:  arm.ProvisioningStateDeleting,
 			expectError:                      false,
 		},
+		{
+			name:                             "Canceled operation is not revived",
+			currentOperationStatus:           arm.ProvisioningStateCanceled,
+			updatedOperationStatus:           arm.ProvisioningStateSucceeded,
+			resourceDocPresent:               true,
+			resourceMatchOperationID:         false,
+			resourceProvisioningState:        arm.ProvisioningStateDeleting,
+			expectAsyncNotification:          false,
+			expectResourceOperationIDCleared: false,
+			expectResourceProvisioningState:  arm.ProvisioningStateDeleting,
+			expectError:                      false,
+		},
 		{
 			name:                             "Operation already at target provisioning state",
 			currentOperationStatus:           arm.ProvisioningStateSucceeded,
@@ -174,6 +198,20 @@ func TestUpdateOperationStatus(t *testing.T) {
 			expectResourceProvisioningState:  arm.ProvisioningStateSucceeded,
 			expectError:                      false,
 		},
+		{
+			name:                             "Progress updated without status change",
+			currentOperationStatus:           arm.ProvisioningStateProvisioning,
+			updatedOperationStatus:           arm.ProvisioningStateProvisioning,
+			resourceDocPresent:               true,
+			resourceMatchOperationID:         true,
+			resourceProvisioningState:        arm.ProvisioningStateProvisioning,
+			percentComplete:                  50,
+			expectAsyncNotification:          false,
+			expectResourceOperationIDCleared: false,
+			expectResourceProvisioningState:  arm.ProvisioningStateProvisioning,
+			expectPercentComplete:            50,
+			expectError:                      false,
+		},
 		{
 			name:                    "Resource not found",
 			currentOperationStatus:  arm.ProvisioningStateProvisioning,
@@ -242,7 +280,7 @@ func TestUpdateOperationStatus(t *testing.T) {
 				_ = scanner.dbClient.CreateResourceDoc(ctx, resourceDoc)
 			}
 
-			err = scanner.updateOperationStatus(ctx, slog.Default(), operationDoc, tt.updatedOperationStatus, nil)
+			err = scanner.updateOperationStatus(ctx, slog.Default(), operationDoc, tt.updatedOperationStatus, nil, tt.percentComplete)
 
 			if request == nil && tt.expectAsyncNotification {
 				t.Error("Did not POST to async notification URI")
@@ -268,6 +306,18 @@ func TestUpdateOperationStatus(t *testing.T) {
 				}
 			}
 
+			if err == nil {
+				operationDoc, getErr := scanner.dbClient.GetOperationDoc(ctx, operationDoc.ID)
+				if getErr != nil {
+					t.Fatal(getErr)
+				}
+				if operationDoc.PercentComplete != tt.expectPercentComplete {
+					t.Errorf("Expected operation percent complete to be %v but got %v",
+						tt.expectPercentComplete,
+						operationDoc.PercentComplete)
+				}
+			}
+
 			if err == nil && tt.resourceDocPresent {
 				resourceDoc, getErr := scanner.dbClient.GetResourceDoc(ctx, resourceID)
 				if getErr != nil {
@@ -446,3 +496,363 @@ func TestConvertClusterStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterCreatePercentComplete(t *testing.T) {
+	tests := []struct {
+		name                  string
+		clusterStatus         *cmv1.ClusterStatusBuilder
+		expectPercentComplete float64
+	}{
+		{
+			name:                  "Pending",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStatePending),
+			expectPercentComplete: 0,
+		},
+		{
+			name:                  "Validating",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateValidating),
+			expectPercentComplete: 10,
+		},
+		{
+			name:                  "Installing",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateInstalling),
+			expectPercentComplete: 25,
+		},
+		{
+			name:                  "Installing with DNS ready",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateInstalling).DNSReady(true),
+			expectPercentComplete: 50,
+		},
+		{
+			name:                  "Installing with DNS and OIDC ready",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateInstalling).DNSReady(true).OIDCReady(true),
+			expectPercentComplete: 75,
+		},
+		{
+			name:                  "Ready",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateReady).DNSReady(true).OIDCReady(true),
+			expectPercentComplete: 100,
+		},
+		{
+			name:                  "Error",
+			clusterStatus:         cmv1.NewClusterStatus().State(cmv1.ClusterStateError),
+			expectPercentComplete: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterStatus, err := tt.clusterStatus.Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			percentComplete := clusterCreatePercentComplete(clusterStatus)
+			if percentComplete != tt.expectPercentComplete {
+				t.Errorf("Expected percent complete to be %v but got %v", tt.expectPercentComplete, percentComplete)
+			}
+		})
+	}
+}
+
+// fakeLockClient grants every lock immediately and never loses it.
+type fakeLockClient struct{}
+
+func (fakeLockClient) GetDefaultTimeToLive() time.Duration {
+	return time.Minute
+}
+
+func (fakeLockClient) AcquireLock(ctx context.Context, id string, timeout *time.Duration) (*azcosmos.ItemResponse, error) {
+	return &azcosmos.ItemResponse{}, nil
+}
+
+func (fakeLockClient) HoldLock(ctx context.Context, item *azcosmos.ItemResponse) (context.Context, database.StopHoldLock) {
+	return ctx, func() *azcosmos.ItemResponse { return item }
+}
+
+func (fakeLockClient) ReleaseLock(ctx context.Context, item *azcosmos.ItemResponse) error {
+	return nil
+}
+
+func TestRunOnce(t *testing.T) {
+	tests := []struct {
+		name                 string
+		internalID           string
+		clusterStatus        *cmv1.ClusterStatusBuilder
+		expectOperationState arm.ProvisioningState
+		expectError          bool
+	}{
+		{
+			name:                 "Node pool operation polled",
+			internalID:           "/api/clusters_mgmt/v1/clusters/placeholder/node_pools/placeholder",
+			expectOperationState: arm.ProvisioningStateAccepted,
+			expectError:          false,
+		},
+		{
+			name:                 "Cluster operation polled",
+			internalID:           "/api/clusters_mgmt/v1/clusters/placeholder",
+			clusterStatus:        cmv1.NewClusterStatus().State(cmv1.ClusterStateReady).DNSReady(true).OIDCReady(true),
+			expectOperationState: arm.ProvisioningStateSucceeded,
+			expectError:          false,
+		},
+		{
+			name:                 "Cluster operation failed to poll",
+			internalID:           "/api/clusters_mgmt/v1/clusters/placeholder",
+			expectOperationState: arm.ProvisioningStateAccepted,
+			expectError:          true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.clusterStatus == nil || r.URL.Path != tt.internalID+"/status" {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				clusterStatus, err := tt.clusterStatus.Build()
+				if err != nil {
+					t.Error(err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				if err := cmv1.MarshalClusterStatus(clusterStatus, w); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer server.Close()
+
+			conn, err := ocmsdk.NewUnauthenticatedConnectionBuilder().
+				URL(server.URL).
+				RetryLimit(0).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			scanner := &OperationsScanner{
+				dbClient:       database.NewCache(),
+				lockClient:     fakeLockClient{},
+				clusterService: ocm.ClusterServiceClient{Conn: conn, MaxAttempts: 1},
+			}
+
+			resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/testCluster")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			internalID, err := ocm.NewInternalID(tt.internalID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			operationDoc := database.NewOperationDocument(database.OperationRequestCreate, resourceID, internalID)
+			err = scanner.dbClient.CreateOperationDoc(ctx, operationDoc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resourceDoc := database.NewResourceDocument(resourceID)
+			resourceDoc.ActiveOperationID = operationDoc.ID
+			err = scanner.dbClient.CreateResourceDoc(ctx, resourceDoc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = scanner.RunOnce(ctx, slog.Default())
+
+			if err == nil && tt.expectError {
+				t.Error("Expected error but got none")
+			} else if err != nil && !tt.expectError {
+				t.Errorf("Got unexpected error: %v", err)
+			}
+
+			operationDoc, err = scanner.dbClient.GetOperationDoc(ctx, operationDoc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if operationDoc.Status != tt.expectOperationState {
+				t.Errorf("Expected operation status %s but got %s", tt.expectOperationState, operationDoc.Status)
+			}
+
+			resourceDoc, err = scanner.dbClient.GetResourceDoc(ctx, resourceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.expectOperationState.IsTerminal() && resourceDoc.ActiveOperationID != "" {
+				t.Errorf("Expected active operation to be cleared but got %s", resourceDoc.ActiveOperationID)
+			}
+		})
+	}
+}
+
+func TestOperationLoggerCorrelation(t *testing.T) {
+	ctx := context.Background()
+
+	resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/testCluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internalID, err := ocm.NewInternalID("/api/clusters_mgmt/v1/clusters/placeholder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := &OperationsScanner{
+		dbClient: database.NewCache(),
+	}
+
+	operationDoc := database.NewOperationDocument(database.OperationRequestCreate, resourceID, internalID)
+	operationDoc.SetCorrelationData(&arm.CorrelationData{
+		ClientRequestID:      "client-request-id",
+		CorrelationRequestID: "correlation-request-id",
+	})
+	_ = scanner.dbClient.CreateOperationDoc(ctx, operationDoc)
+
+	resourceDoc := database.NewResourceDocument(resourceID)
+	resourceDoc.ActiveOperationID = operationDoc.ID
+	_ = scanner.dbClient.CreateResourceDoc(ctx, resourceDoc)
+
+	var buf bytes.Buffer
+	logger := operationLogger(slog.New(slog.NewJSONHandler(&buf, nil)), operationDoc)
+
+	err = scanner.updateOperationStatus(ctx, logger, operationDoc, arm.ProvisioningStateSucceeded, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var records int
+	var transitionLogged bool
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records++
+		if record["correlation_request_id"] != "correlation-request-id" {
+			t.Errorf("Expected correlation_request_id in %v", record)
+		}
+		if record["client_request_id"] != "client-request-id" {
+			t.Errorf("Expected client_request_id in %v", record)
+		}
+		if record["operation_id"] != operationDoc.ID {
+			t.Errorf("Expected operation_id in %v", record)
+		}
+		if record["previous_status"] == string(arm.ProvisioningStateAccepted) && record["status"] == string(arm.ProvisioningStateSucceeded) {
+			transitionLogged = true
+		}
+	}
+	if records == 0 {
+		t.Fatal("Expected log records")
+	}
+	if !transitionLogged {
+		t.Error("Expected the status transition to be logged")
+	}
+}
+
+func TestPollOperationTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	conn, err := ocmsdk.NewUnauthenticatedConnectionBuilder().
+		URL(server.URL).
+		RetryLimit(0).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/testCluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internalID, err := ocm.NewInternalID("/api/clusters_mgmt/v1/clusters/placeholder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	operationDoc := database.NewOperationDocument(database.OperationRequestCreate, resourceID, internalID)
+
+	scanner := &OperationsScanner{
+		dbClient:         database.NewCache(),
+		clusterService:   ocm.ClusterServiceClient{Conn: conn, MaxAttempts: 1},
+		activeOperations: []*database.OperationDocument{operationDoc},
+	}
+	_ = scanner.dbClient.CreateOperationDoc(ctx, operationDoc)
+
+	resourceDoc := database.NewResourceDocument(resourceID)
+	resourceDoc.ActiveOperationID = operationDoc.ID
+	_ = scanner.dbClient.CreateResourceDoc(ctx, resourceDoc)
+
+	// The Cluster Service call fails, so the poll ends there.
+	if failed := scanner.pollCSOperations(ctx, slog.Default(), nil); failed != 1 {
+		t.Fatalf("Expected 1 failed operation, got %d", failed)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+
+	csSpan, operationSpan := spans[0], spans[1]
+	if operationSpan.Name() != "poll "+string(database.OperationRequestCreate) {
+		t.Errorf("Unexpected operation span name %q", operationSpan.Name())
+	}
+	if operationSpan.Status().Code != codes.Error {
+		t.Error("Expected the operation span to record the error")
+	}
+
+	attributes := make(map[string]string)
+	for _, attr := range operationSpan.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attributes["aro.operation_id"] != operationDoc.ID {
+		t.Errorf("Expected operation ID attribute %q, got %q", operationDoc.ID, attributes["aro.operation_id"])
+	}
+	if attributes["aro.resource_id"] != resourceID.String() {
+		t.Errorf("Expected resource ID attribute %q, got %q", resourceID, attributes["aro.resource_id"])
+	}
+
+	if csSpan.Name() != "clusterservice.GetCSClusterStatus" {
+		t.Errorf("Unexpected Cluster Service span name %q", csSpan.Name())
+	}
+	if csSpan.Parent().SpanID() != operationSpan.SpanContext().SpanID() {
+		t.Error("Expected the Cluster Service span to be a child of the operation span")
+	}
+
+	// Updating the operation status writes to Cosmos DB twice.
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "test")
+	err = scanner.updateOperationStatus(ctx, slog.Default(), operationDoc, arm.ProvisioningStateSucceeded, nil, 0)
+	span.End()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var databaseSpans []string
+	for _, s := range recorder.Ended()[2:] {
+		if s.Parent().SpanID() == span.SpanContext().SpanID() {
+			databaseSpans = append(databaseSpans, s.Name())
+		}
+	}
+	expectedSpans := []string{"database.UpdateOperationDoc", "database.UpdateResourceDoc"}
+	if !reflect.DeepEqual(databaseSpans, expectedSpans) {
+		t.Errorf("Expected database spans %v, got %v", expectedSpans, databaseSpans)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+const tracerName = "github.com/Azure/ARO-HCP/backend"
+
+// operationSpanAttributes returns span attributes identifying the
+// operation and its resource, mirroring operationLogger.
+func operationSpanAttributes(doc *database.OperationDocument) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("aro.operation", string(doc.Request)),
+		attribute.String("aro.operation_id", doc.ID),
+		attribute.String("aro.resource_id", doc.ExternalID.String()),
+		attribute.String("aro.internal_id", doc.InternalID.String()),
+	}
+	if doc.CorrelationRequestID != "" {
+		attrs = append(attrs, attribute.String("aro.correlation_request_id", doc.CorrelationRequestID))
+	}
+	return attrs
+}
@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"runtime/debug"
 	"syscall"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
@@ -23,6 +24,7 @@ import (
 	"github.com/Azure/ARO-HCP/internal/api"
 	"github.com/Azure/ARO-HCP/internal/database"
 	"github.com/Azure/ARO-HCP/internal/ocm"
+	"github.com/Azure/ARO-HCP/internal/tracing"
 )
 
 type FrontendOpts struct {
@@ -30,6 +32,7 @@ type FrontendOpts struct {
 	clusterServiceProvisionShard  string
 	clusterServiceNoopProvision   bool
 	clusterServiceNoopDeprovision bool
+	clusterServiceMaxAttempts     int
 	insecure                      bool
 
 	location    string
@@ -39,6 +42,18 @@ type FrontendOpts struct {
 	useCache   bool
 	cosmosName string
 	cosmosURL  string
+
+	adminClientIDs        []string
+	maxPageSize           int32
+	operationPollInterval time.Duration
+	operationPollThrottle bool
+
+	maxNodePoolVersionSkew  int
+	maxRequestBodySize      int64
+	maxSubscriptionRequests int
+	operationTTL            time.Duration
+
+	tracingEndpoint string
 }
 
 func NewRootCmd() *cobra.Command {
@@ -73,6 +88,17 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.Flags().StringVar(&opts.clusterServiceProvisionShard, "cluster-service-provision-shard", "", "Manually specify provision shard for all requests to cluster service")
 	rootCmd.Flags().BoolVar(&opts.clusterServiceNoopProvision, "cluster-service-noop-provision", false, "Skip cluster service provisioning steps for development purposes")
 	rootCmd.Flags().BoolVar(&opts.clusterServiceNoopDeprovision, "cluster-service-noop-deprovision", false, "Skip cluster service deprovisioning steps for development purposes")
+	rootCmd.Flags().IntVar(&opts.clusterServiceMaxAttempts, "cluster-service-max-attempts", ocm.DefaultMaxAttempts, "Maximum number of attempts for cluster service requests failing with a transient error")
+
+	rootCmd.Flags().StringSliceVar(&opts.adminClientIDs, "admin-client-ids", nil, "Client object IDs permitted to use admin-only debugging features")
+	rootCmd.Flags().Int32Var(&opts.maxPageSize, "max-page-size", frontend.DefaultMaxPageSize, "Maximum number of items returned in a single page of a list response")
+	rootCmd.Flags().DurationVar(&opts.operationPollInterval, "operation-poll-interval", 0, "Minimum interval between status polls of an asynchronous operation, advertised via Retry-After (tracked by each replica separately, 0 disables)")
+	rootCmd.Flags().BoolVar(&opts.operationPollThrottle, "operation-poll-throttle", false, "Reject operation status polls arriving sooner than the minimum interval with 429 Too Many Requests (enforced by each replica separately)")
+	rootCmd.Flags().IntVar(&opts.maxNodePoolVersionSkew, "max-node-pool-version-skew", frontend.DefaultMaxNodePoolVersionSkew, "Maximum number of minor versions a new node pool may lag behind its cluster (negative disables the check)")
+	rootCmd.Flags().Int64Var(&opts.maxRequestBodySize, "max-request-body-size", frontend.DefaultMaxRequestBodySize, "Maximum size in bytes of a request body; larger requests are rejected with 413 Payload Too Large")
+	rootCmd.Flags().IntVar(&opts.maxSubscriptionRequests, "max-subscription-requests", frontend.DefaultMaxSubscriptionRequests, "Maximum number of concurrent mutating requests per subscription; more are rejected with 429 Too Many Requests (enforced by each replica separately, 0 disables the limit)")
+	rootCmd.Flags().DurationVar(&opts.operationTTL, "operation-ttl", database.DefaultOperationTimeToLive, "How long to keep operation documents after the operation finishes (0 defers to the container default)")
+	rootCmd.Flags().StringVar(&opts.tracingEndpoint, "tracing-endpoint", os.Getenv(tracing.EndpointEnvVar), "URL of an OTLP/HTTP collector to export request traces to (empty disables tracing)")
 
 	rootCmd.MarkFlagsMutuallyExclusive("use-cache", "cosmos-name")
 	rootCmd.MarkFlagsMutuallyExclusive("use-cache", "cosmos-url")
@@ -85,6 +111,16 @@ func (opts *FrontendOpts) Run() error {
 	logger := config.DefaultLogger()
 	logger.Info(fmt.Sprintf("%s (%s) started", frontend.ProgramName, version()))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), opts.tracingEndpoint, "aro-hcp-frontend")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error(fmt.Sprintf("shutting down tracing failed: %v", err))
+		}
+	}()
+
 	// Init prometheus emitter
 	prometheusEmitter := frontend.NewPrometheusEmitter(prometheus.DefaultRegisterer)
 
@@ -119,7 +155,7 @@ func (opts *FrontendOpts) Run() error {
 			return err
 		}
 
-		dbClient, err = database.NewCosmosDBClient(context.Background(), cosmosDatabaseClient)
+		dbClient, err = database.NewCosmosDBClient(context.Background(), cosmosDatabaseClient, opts.operationTTL, prometheus.DefaultRegisterer)
 		if err != nil {
 			return fmt.Errorf("creating the database client failed: %v", err)
 		}
@@ -136,9 +172,12 @@ func (opts *FrontendOpts) Run() error {
 	}
 
 	// Initialize Clusters Service Client
+	// Requests are retried by ocm.ClusterServiceClient, which knows
+	// which requests are safe to retry, so disable the SDK's retries.
 	conn, err := sdk.NewUnauthenticatedConnectionBuilder().
 		URL(opts.clustersServiceURL).
 		Insecure(opts.insecure).
+		RetryLimit(0).
 		Build()
 	if err != nil {
 		return err
@@ -148,6 +187,7 @@ func (opts *FrontendOpts) Run() error {
 		Conn:                       conn,
 		ProvisionerNoOpProvision:   opts.clusterServiceNoopDeprovision,
 		ProvisionerNoOpDeprovision: opts.clusterServiceNoopDeprovision,
+		MaxAttempts:                opts.clusterServiceMaxAttempts,
 	}
 
 	if opts.clusterServiceProvisionShard != "" {
@@ -159,7 +199,16 @@ func (opts *FrontendOpts) Run() error {
 	}
 	logger.Info(fmt.Sprintf("Application running in %s", opts.location))
 
-	f := frontend.NewFrontend(logger, listener, metricsListener, prometheusEmitter, dbClient, opts.location, &csClient)
+	f := frontend.NewFrontend(logger, listener, metricsListener, prometheusEmitter, dbClient, opts.location, &csClient, frontend.FrontendOptions{
+		AdminClientIDs:          opts.adminClientIDs,
+		MaxPageSize:             opts.maxPageSize,
+		OperationPollInterval:   opts.operationPollInterval,
+		OperationPollThrottle:   opts.operationPollThrottle,
+		MaxNodePoolVersionSkew:  opts.maxNodePoolVersionSkew,
+		MaxRequestBodySize:      opts.maxRequestBodySize,
+		MaxSubscriptionRequests: opts.maxSubscriptionRequests,
+		Tracing:                 opts.tracingEndpoint != "",
+	})
 
 	stop := make(chan struct{})
 	signalChannel := make(chan os.Signal, 1)
@@ -0,0 +1,186 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+// AdminActionReconcile is the trailing path segment of the admin
+// endpoint that reconciles a cluster's resource document with the
+// cluster as stored in Cluster Service.
+const AdminActionReconcile = "reconcile"
+
+// reconcileChange describes one resource document field that was
+// rewritten by a reconcile request.
+type reconcileChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// reconcileResponse is the response body of a reconcile request.
+type reconcileResponse struct {
+	Changes []reconcileChange `json:"changes"`
+}
+
+// clusterTerminalProvisioningState returns the terminal provisioning state
+// corresponding to a Cluster Service cluster state, or an empty string if
+// the cluster state is not terminal. Non-terminal states are owned by the
+// backend while an operation is active and are never reconciled.
+func clusterTerminalProvisioningState(state cmv1.ClusterState) arm.ProvisioningState {
+	switch state {
+	case cmv1.ClusterStateReady:
+		return arm.ProvisioningStateSucceeded
+	case cmv1.ClusterStateError:
+		return arm.ProvisioningStateFailed
+	default:
+		return ""
+	}
+}
+
+// Outcomes recorded in admin action audit entries.
+const (
+	adminActionOutcomeForbidden = "forbidden"
+	adminActionOutcomeFailed    = "failed"
+	adminActionOutcomeSucceeded = "succeeded"
+)
+
+// auditAdminAction writes a structured audit log entry for an admin action.
+// Admin actions create no customer-visible operation, so this entry is the
+// only trace they leave. Every attempt is recorded, including forbidden and
+// failed ones.
+func auditAdminAction(ctx context.Context, action, actor, resource, outcome string, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.String("action", action),
+		slog.String("actor", actor),
+		slog.String("resource", resource),
+		slog.String("outcome", outcome),
+	}, attrs...)
+	LoggerFromContext(ctx).LogAttrs(ctx, slog.LevelInfo, "Admin action audit", attrs...)
+}
+
+// reconcileClusterDoc rewrites the fields of a cluster resource document
+// that are derived from Cluster Service and returns the changes made.
+func reconcileClusterDoc(doc *database.ResourceDocument, csCluster *cmv1.Cluster) []reconcileChange {
+	changes := []reconcileChange{}
+
+	// An active operation will update the provisioning
+	// state itself once the backend observes it complete.
+	if doc.ActiveOperationID == "" {
+		provisioningState := clusterTerminalProvisioningState(csCluster.Status().State())
+		if provisioningState != "" && provisioningState != doc.ProvisioningState {
+			changes = append(changes, reconcileChange{
+				Field:    "provisioningState",
+				OldValue: string(doc.ProvisioningState),
+				NewValue: string(provisioningState),
+			})
+			doc.ProvisioningState = provisioningState
+		}
+	}
+
+	return changes
+}
+
+// AdminClusterReconcile re-fetches a cluster from Cluster Service and
+// rewrites the derived fields of its resource document to match. This
+// gives SREs a supported repair path when the two drift apart, such as
+// after a partially failed request. Only admin clients may call it.
+func (f *Frontend) AdminClusterReconcile(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	logger := LoggerFromContext(ctx)
+
+	clientID := request.Header.Get(arm.HeaderNameClientObjectID)
+
+	originalPath, err := OriginalPathFromContext(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	// The request path is the cluster resource ID
+	// followed by the reconcile action segment.
+	resource := path.Dir(originalPath)
+
+	outcome := adminActionOutcomeFailed
+	changes := []reconcileChange{}
+	defer func() {
+		auditAdminAction(ctx, AdminActionReconcile, clientID, resource, outcome,
+			slog.Int("changes", len(changes)))
+	}()
+
+	if !f.IsAdminRequest(request) {
+		outcome = adminActionOutcomeForbidden
+		arm.WriteError(writer, http.StatusForbidden,
+			arm.CloudErrorCodeForbidden, "",
+			"The client is not authorized to perform this action")
+		return
+	}
+
+	resourceID, err := arm.ParseResourceID(resource)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
+	if err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, database.ErrNotFound) {
+			arm.WriteResourceNotFoundError(writer, resourceID)
+		} else {
+			writeDatabaseError(writer, err)
+		}
+		return
+	}
+
+	csCluster, err := f.clusterServiceClient.GetCSCluster(ctx, doc.InternalID)
+	if err != nil {
+		logger.Error(err.Error())
+		var ocmError *ocmerrors.Error
+		if errors.As(err, &ocmError) && ocmError.Status() == http.StatusNotFound {
+			arm.WriteResourceNotFoundError(writer, resourceID)
+		} else {
+			arm.WriteInternalServerError(writer)
+		}
+		return
+	}
+
+	_, err = f.dbClient.UpdateResourceDoc(ctx, resourceID, func(updateDoc *database.ResourceDocument) bool {
+		changes = reconcileClusterDoc(updateDoc, csCluster)
+		return len(changes) > 0
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		writeDatabaseError(writer, err)
+		return
+	}
+
+	for _, change := range changes {
+		logger.Info(fmt.Sprintf("Reconciled %s of '%s': '%s' -> '%s'", change.Field, resourceID, change.OldValue, change.NewValue))
+	}
+	if len(changes) == 0 {
+		logger.Info(fmt.Sprintf("Resource document for '%s' is already in sync", resourceID))
+	}
+
+	outcome = adminActionOutcomeSucceeded
+
+	_, err = arm.WriteJSONResponse(writer, http.StatusOK, reconcileResponse{Changes: changes})
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
@@ -0,0 +1,206 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestAdminClusterReconcile(t *testing.T) {
+	const (
+		adminClientID = "00000000-0000-0000-0000-00000000000a"
+		otherClientID = "00000000-0000-0000-0000-00000000000b"
+		clusterName   = "testCluster"
+	)
+
+	resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/" + clusterName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                      string
+		clientID                  string
+		docExists                 bool
+		activeOperationID         string
+		provisioningState         arm.ProvisioningState
+		expectedStatusCode        int
+		expectedProvisioningState arm.ProvisioningState
+		expectedChanges           int
+		expectedOutcome           string
+	}{
+		{
+			name:               "Non-admin client is rejected",
+			clientID:           otherClientID,
+			docExists:          true,
+			provisioningState:  arm.ProvisioningStateFailed,
+			expectedStatusCode: http.StatusForbidden,
+			// The document must not be touched.
+			expectedProvisioningState: arm.ProvisioningStateFailed,
+			expectedOutcome:           adminActionOutcomeForbidden,
+		},
+		{
+			name:               "Missing resource document",
+			clientID:           adminClientID,
+			docExists:          false,
+			expectedStatusCode: http.StatusNotFound,
+			expectedOutcome:    adminActionOutcomeFailed,
+		},
+		{
+			name:                      "Drifted provisioning state is repaired",
+			clientID:                  adminClientID,
+			docExists:                 true,
+			provisioningState:         arm.ProvisioningStateFailed,
+			expectedStatusCode:        http.StatusOK,
+			expectedProvisioningState: arm.ProvisioningStateSucceeded,
+			expectedChanges:           1,
+			expectedOutcome:           adminActionOutcomeSucceeded,
+		},
+		{
+			name:                      "Document already in sync",
+			clientID:                  adminClientID,
+			docExists:                 true,
+			provisioningState:         arm.ProvisioningStateSucceeded,
+			expectedStatusCode:        http.StatusOK,
+			expectedProvisioningState: arm.ProvisioningStateSucceeded,
+			expectedChanges:           0,
+			expectedOutcome:           adminActionOutcomeSucceeded,
+		},
+		{
+			name:                      "Active operation leaves provisioning state alone",
+			clientID:                  adminClientID,
+			docExists:                 true,
+			activeOperationID:         "11111111-1111-1111-1111-111111111111",
+			provisioningState:         arm.ProvisioningStateUpdating,
+			expectedStatusCode:        http.StatusOK,
+			expectedProvisioningState: arm.ProvisioningStateUpdating,
+			expectedChanges:           0,
+			expectedOutcome:           adminActionOutcomeSucceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			mockCSClient := ocm.NewMockClusterServiceClient()
+			csCluster, err := cmv1.NewCluster().
+				Name(clusterName).
+				Status(cmv1.NewClusterStatus().State(cmv1.ClusterStateReady)).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			csCluster, err = mockCSClient.PostCSCluster(ctx, csCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			f := &Frontend{
+				dbClient:             database.NewCache(),
+				clusterServiceClient: &mockCSClient,
+				adminClientIDs:       []string{adminClientID},
+			}
+
+			if tt.docExists {
+				doc := database.NewResourceDocument(resourceID)
+				doc.InternalID, err = ocm.NewInternalID(csCluster.HREF())
+				if err != nil {
+					t.Fatal(err)
+				}
+				doc.ActiveOperationID = tt.activeOperationID
+				doc.ProvisioningState = tt.provisioningState
+				err = f.dbClient.CreateResourceDoc(ctx, doc)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			originalPath := resourceID.String() + "/" + AdminActionReconcile
+			request := httptest.NewRequest(http.MethodPost, originalPath, nil)
+			var logBuffer bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&logBuffer, nil))
+			ctx = ContextWithLogger(ctx, logger)
+			ctx = ContextWithOriginalPath(ctx, originalPath)
+			request = request.WithContext(ctx)
+			request.Header.Set(arm.HeaderNameClientObjectID, tt.clientID)
+
+			writer := httptest.NewRecorder()
+
+			f.AdminClusterReconcile(writer, request)
+
+			if writer.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, writer.Code)
+			}
+
+			if writer.Code == http.StatusOK {
+				var response reconcileResponse
+				err = json.Unmarshal(writer.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(response.Changes) != tt.expectedChanges {
+					t.Errorf("expected %d changes, got %+v", tt.expectedChanges, response.Changes)
+				}
+			}
+
+			var audits []map[string]any
+			for _, line := range bytes.Split(bytes.TrimSpace(logBuffer.Bytes()), []byte("\n")) {
+				var entry map[string]any
+				err = json.Unmarshal(line, &entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if entry[slog.MessageKey] == "Admin action audit" {
+					audits = append(audits, entry)
+				}
+			}
+			if len(audits) != 1 {
+				t.Fatalf("expected 1 audit entry, got %d", len(audits))
+			}
+			expectedAudit := map[string]any{
+				"action":   AdminActionReconcile,
+				"actor":    tt.clientID,
+				"resource": resourceID.String(),
+				"outcome":  tt.expectedOutcome,
+			}
+			for key, value := range expectedAudit {
+				if audits[0][key] != value {
+					t.Errorf("expected audit %s '%v', got '%v'", key, value, audits[0][key])
+				}
+			}
+
+			// Reconciling must never create a customer-visible operation.
+			iterator := f.dbClient.ListAllOperationDocs(ctx)
+			for range iterator.Items(ctx) {
+				t.Error("unexpected operation document")
+			}
+			if err := iterator.GetError(); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.docExists {
+				doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if doc.ProvisioningState != tt.expectedProvisioningState {
+					t.Errorf("expected provisioning state %s, got %s", tt.expectedProvisioningState, doc.ProvisioningState)
+				}
+			}
+		})
+	}
+}
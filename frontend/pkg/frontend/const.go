@@ -3,12 +3,52 @@ package frontend
 // Copyright (c) Microsoft Corporation.
 // Licensed under the Apache License 2.0.
 
+import "time"
+
 const (
 	ProgramName = "ARO HCP Frontend"
 
 	// APIVersionKey is the request parameter name for the API version.
 	APIVersionKey = "api-version"
 
+	// DefaultMaxPageSize is the largest number of items returned in a
+	// single page of a collection GET response, regardless of $top.
+	DefaultMaxPageSize int32 = 100
+
+	// MaxExpandedNodePools is the largest number of node pools embedded
+	// in a cluster GET response with $expand=nodePools. Clusters having
+	// more node pools must page through the node pool collection.
+	MaxExpandedNodePools int32 = 50
+
+	// DefaultMaxRequestBodySize is the largest request body accepted,
+	// in bytes. This matches the largest request body ARM accepts, which
+	// is 4 MB (assuming units in powers of 2).
+	// See https://github.com/Azure/azure-resource-manager-rpc/blob/master/v1.0/common-api-details.md#max-request-body-size
+	DefaultMaxRequestBodySize = 4 * megabyte
+
+	// DefaultMaxSubscriptionRequests is the number of mutating requests
+	// that may be in flight for a single subscription. Further requests
+	// are rejected with 429 Too Many Requests until some complete.
+	DefaultMaxSubscriptionRequests = 10
+
+	// DefaultMaxNodePoolVersionSkew is the number of minor versions a
+	// node pool may lag behind the cluster control plane.
+	DefaultMaxNodePoolVersionSkew = 2
+
+	// healthCheckTimeout bounds the dependency checks made by the
+	// health endpoint, which is polled frequently.
+	healthCheckTimeout = 3 * time.Second
+
+	// HeaderNameDebugInternalCluster is a request header that, when sent by
+	// an admin client, causes the normalized internal cluster representation
+	// to be echoed back in a response header of the same name.
+	HeaderNameDebugInternalCluster = "X-Ms-Arohcp-Debug-Internal-Cluster"
+
+	// HeaderNameValidationWarning is a response header carrying a validation
+	// warning. The header is repeated for each warning. Warnings describe
+	// likely unintended request content that does not fail the request.
+	HeaderNameValidationWarning = "X-Ms-Arohcp-Validation-Warning"
+
 	// Wildcard path segment names for request multiplexing, must be lowercase as we lowercase the request URL pattern when registering handlers
 	PathSegmentActionName        = "actionname"
 	PathSegmentDeploymentName    = "deploymentname"
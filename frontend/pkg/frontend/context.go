@@ -67,6 +67,7 @@ func BodyFromContext(ctx context.Context) ([]byte, error) {
 }
 
 func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	ctx = database.ContextWithLogger(ctx, logger)
 	return context.WithValue(ctx, contextKeyLogger, logger)
 }
 
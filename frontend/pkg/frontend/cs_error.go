@@ -0,0 +1,103 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+)
+
+// csAttributePattern matches the attribute a Cluster Service validation
+// error names in its reason, such as "Attribute 'network.machine_cidr' is
+// not a valid CIDR". Attributes are named by their Cluster Service JSON
+// path, which is what the target tables below are keyed by.
+var csAttributePattern = regexp.MustCompile(`(?i)\battribute '([a-z0-9_.]+)'`)
+
+// Cluster Service attribute paths mapped to the ARM property paths of the
+// corresponding fields. Keep these in step with the fields BuildCSCluster
+// and BuildCSNodePool send to Cluster Service.
+var (
+	csClusterAttributeTargets = map[string]string{
+		"version.id":                               "properties.spec.version.id",
+		"version.channel_group":                    "properties.spec.version.channelGroup",
+		"domain_prefix":                            "properties.spec.dns.baseDomainPrefix",
+		"network.type":                             "properties.spec.network.networkType",
+		"network.pod_cidr":                         "properties.spec.network.podCidr",
+		"network.service_cidr":                     "properties.spec.network.serviceCidr",
+		"network.machine_cidr":                     "properties.spec.network.machineCidr",
+		"network.host_prefix":                      "properties.spec.network.hostPrefix",
+		"api.listening":                            "properties.spec.api.visibility",
+		"fips":                                     "properties.spec.fips",
+		"etcd_encryption":                          "properties.spec.etcdEncryption",
+		"disable_user_workload_monitoring":         "properties.spec.disableUserWorkloadMonitoring",
+		"proxy.http_proxy":                         "properties.spec.proxy.httpProxy",
+		"proxy.https_proxy":                        "properties.spec.proxy.httpsProxy",
+		"proxy.no_proxy":                           "properties.spec.proxy.noProxy",
+		"additional_trust_bundle":                  "properties.spec.proxy.trustedCa",
+		"azure.managed_resource_group_name":        "properties.spec.platform.managedResourceGroup",
+		"azure.subnet_resource_id":                 "properties.spec.platform.subnetId",
+		"azure.network_security_group_resource_id": "properties.spec.platform.networkSecurityGroupId",
+	}
+	csNodePoolAttributeTargets = map[string]string{
+		"version.id":                                   "properties.spec.version.id",
+		"version.channel_group":                        "properties.spec.version.channelGroup",
+		"subnet":                                       "properties.spec.platform.subnetId",
+		"availability_zone":                            "properties.spec.platform.availabilityZone",
+		"azure_node_pool.vm_size":                      "properties.spec.platform.vmSize",
+		"azure_node_pool.os_disk_size_gibibytes":       "properties.spec.platform.diskSizeGiB",
+		"azure_node_pool.os_disk_storage_account_type": "properties.spec.platform.diskStorageAccountType",
+		"azure_node_pool.ephemeral_os_disk_enabled":    "properties.spec.platform.ephemeralOsDisk",
+		"replicas":                                     "properties.spec.replicas",
+		"autoscaling.min_replica":                      "properties.spec.autoScaling.min",
+		"autoscaling.max_replica":                      "properties.spec.autoScaling.max",
+		"auto_repair":                                  "properties.spec.autoRepair",
+		"labels":                                       "properties.spec.labels",
+		"taints":                                       "properties.spec.taints",
+		"tuning_configs":                               "properties.spec.tuningConfigs",
+	}
+)
+
+// CSErrorToCloudError converts an error returned by Cluster Service while
+// creating or updating a resource to a CloudError. A validation error that
+// names an attribute found in attributeTargets is passed on to the client
+// with the target set to the ARM property path of that attribute, and with
+// the attribute renamed to that path in the message. Other validation
+// errors and conflicts get fixed messages, so that internal details of the
+// reason are not passed on to the client. The original error should be
+// logged by the caller. Unrecognized errors are reported as an internal
+// server error.
+func CSErrorToCloudError(err error, attributeTargets map[string]string) *arm.CloudError {
+	var ocmError *ocmerrors.Error
+	if errors.As(err, &ocmError) {
+		switch ocmError.Status() {
+		case http.StatusBadRequest:
+			reason := ocmError.Reason()
+			if match := csAttributePattern.FindStringSubmatchIndex(reason); match != nil {
+				attribute := strings.ToLower(reason[match[2]:match[3]])
+				if target, ok := attributeTargets[attribute]; ok {
+					return arm.NewCloudError(
+						http.StatusBadRequest,
+						arm.CloudErrorCodeInvalidRequestContent,
+						target, "%s", reason[:match[2]]+target+reason[match[3]:])
+				}
+			}
+			return arm.NewCloudError(
+				http.StatusBadRequest,
+				arm.CloudErrorCodeInvalidRequestContent, "",
+				"The requested configuration is not supported")
+		case http.StatusConflict:
+			return arm.NewCloudError(
+				http.StatusConflict,
+				arm.CloudErrorCodeConflict, "",
+				"The request conflicts with the current state of the resource")
+		}
+	}
+	return arm.NewInternalServerError()
+}
@@ -0,0 +1,125 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+)
+
+func TestCSErrorToCloudError(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		wrap               bool
+		attributeTargets   map[string]string
+		expectedStatusCode int
+		expectedCode       string
+		expectedTarget     string
+		expectedMessage    string
+	}{
+		{
+			name:               "Cluster network attribute",
+			body:               `{"kind":"Error","id":"400","code":"CLUSTERS-MGMT-400","status":400,"reason":"Attribute 'network.machine_cidr' overlaps with attribute 'network.service_cidr'"}`,
+			attributeTargets:   csClusterAttributeTargets,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       arm.CloudErrorCodeInvalidRequestContent,
+			expectedTarget:     "properties.spec.network.machineCidr",
+			expectedMessage:    "Attribute 'properties.spec.network.machineCidr' overlaps with attribute 'network.service_cidr'",
+		},
+		{
+			name:               "Cluster Azure attribute",
+			body:               `{"kind":"Error","id":"400","code":"CLUSTERS-MGMT-400","status":400,"reason":"Attribute 'azure.subnet_resource_id' must be a valid subnet resource ID"}`,
+			attributeTargets:   csClusterAttributeTargets,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       arm.CloudErrorCodeInvalidRequestContent,
+			expectedTarget:     "properties.spec.platform.subnetId",
+			expectedMessage:    "Attribute 'properties.spec.platform.subnetId' must be a valid subnet resource ID",
+		},
+		{
+			name:               "Wrapped node pool attribute",
+			body:               `{"kind":"Error","id":"400","code":"CLUSTERS-MGMT-400","status":400,"reason":"Attribute 'azure_node_pool.vm_size' value 'Standard_Z1' is not supported"}`,
+			wrap:               true,
+			attributeTargets:   csNodePoolAttributeTargets,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       arm.CloudErrorCodeInvalidRequestContent,
+			expectedTarget:     "properties.spec.platform.vmSize",
+			expectedMessage:    "Attribute 'properties.spec.platform.vmSize' value 'Standard_Z1' is not supported",
+		},
+		{
+			name:               "Attribute of another resource type",
+			body:               `{"kind":"Error","id":"400","code":"CLUSTERS-MGMT-400","status":400,"reason":"Attribute 'network.machine_cidr' is invalid"}`,
+			attributeTargets:   csNodePoolAttributeTargets,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       arm.CloudErrorCodeInvalidRequestContent,
+			expectedMessage:    "The requested configuration is not supported",
+		},
+		{
+			name:               "Validation error without an attribute",
+			body:               `{"kind":"Error","id":"400","code":"CLUSTERS-MGMT-400","status":400,"reason":"Provision shard 'internal-shard-1' has no capacity"}`,
+			attributeTargets:   csClusterAttributeTargets,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       arm.CloudErrorCodeInvalidRequestContent,
+			expectedMessage:    "The requested configuration is not supported",
+		},
+		{
+			name:               "Conflict",
+			body:               `{"kind":"Error","id":"409","code":"CLUSTERS-MGMT-409","status":409,"reason":"Cluster 'abc123' is being deleted"}`,
+			attributeTargets:   csClusterAttributeTargets,
+			expectedStatusCode: http.StatusConflict,
+			expectedCode:       arm.CloudErrorCodeConflict,
+			expectedMessage:    "The request conflicts with the current state of the resource",
+		},
+		{
+			name:               "Other error",
+			body:               `{"kind":"Error","id":"503","code":"CLUSTERS-MGMT-503","status":503,"reason":"Attribute 'network.machine_cidr' could not be checked"}`,
+			attributeTargets:   csClusterAttributeTargets,
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCode:       arm.CloudErrorCodeInternalServerError,
+			expectedMessage:    arm.NewInternalServerError().Message,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ocmError, err := ocmerrors.UnmarshalError(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = ocmError
+			if tt.wrap {
+				err = fmt.Errorf("failed to create node pool: %w", err)
+			}
+
+			cloudError := CSErrorToCloudError(err, tt.attributeTargets)
+
+			if cloudError.StatusCode != tt.expectedStatusCode {
+				t.Errorf("expected status code %d, got %d", tt.expectedStatusCode, cloudError.StatusCode)
+			}
+			if cloudError.Code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, cloudError.Code)
+			}
+			if cloudError.Target != tt.expectedTarget {
+				t.Errorf("expected target '%s', got '%s'", tt.expectedTarget, cloudError.Target)
+			}
+			if cloudError.Message != tt.expectedMessage {
+				t.Errorf("expected message '%s', got '%s'", tt.expectedMessage, cloudError.Message)
+			}
+		})
+	}
+
+	t.Run("Non-Cluster Service error", func(t *testing.T) {
+		cloudError := CSErrorToCloudError(errors.New("connection refused"), csClusterAttributeTargets)
+		if cloudError.Code != arm.CloudErrorCodeInternalServerError {
+			t.Errorf("expected code %s, got %s", arm.CloudErrorCodeInternalServerError, cloudError.Code)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+const (
+	// ExpandKey is the request parameter name for related
+	// resources to embed in a resource GET response.
+	ExpandKey = "$expand"
+
+	// ExpandNodePools embeds a cluster's node pools in the
+	// cluster GET response under a property of the same name.
+	ExpandNodePools = "nodePools"
+)
+
+// parseExpand returns true if a comma-separated $expand value asks for
+// node pools. An error is returned for any other expansion, or if the
+// resource type has nothing to expand.
+func parseExpand(value string, resourceType string) (bool, error) {
+	var expandNodePools bool
+
+	if value == "" {
+		return false, nil
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case strings.EqualFold(item, ExpandNodePools) && strings.EqualFold(resourceType, api.ClusterResourceType.String()):
+			expandNodePools = true
+		default:
+			return false, fmt.Errorf("unsupported $expand value '%s' for resource type '%s'", item, resourceType)
+		}
+	}
+
+	return expandNodePools, nil
+}
+
+// expandNodePools returns a page of the cluster's node pools, in the same
+// format as a node pool collection GET response. At most MaxExpandedNodePools
+// are included. If the cluster has more, the next link of the page continues
+// through the node pool collection. baseURL is the cluster's request URL.
+func (f *Frontend) expandNodePools(ctx context.Context, clusterDoc *database.ResourceDocument, versionedInterface api.Version, baseURL string) (*arm.PagedResponse, error) {
+	pagedResponse := &arm.PagedResponse{Value: []json.RawMessage{}}
+
+	dbIterator := f.dbClient.ListResourceDocs(ctx, clusterDoc.Key, nil, MaxExpandedNodePools, nil)
+
+	// Build a map of node pool documents by Cluster Service node pool ID.
+	documentMap := make(map[string]*database.ResourceDocument)
+	for item := range dbIterator.Items(ctx) {
+		var doc database.ResourceDocument
+
+		err := json.Unmarshal(item, &doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if doc.InternalID.Kind() == cmv1.NodePoolKind {
+			documentMap[doc.InternalID.ID()] = &doc
+		}
+	}
+
+	err := dbIterator.GetError()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(documentMap) == 0 {
+		return pagedResponse, nil
+	}
+
+	// Build a Cluster Service query that looks for
+	// the specific IDs returned by the Cosmos query.
+	queryIDs := make([]string, 0, len(documentMap))
+	for key := range documentMap {
+		queryIDs = append(queryIDs, "'"+key+"'")
+	}
+	query := fmt.Sprintf("id in (%s)", strings.Join(queryIDs, ", "))
+
+	csIterator := f.clusterServiceClient.ListCSNodePools(clusterDoc.InternalID, query)
+
+	for csNodePool := range csIterator.Items(ctx) {
+		if doc, ok := documentMap[csNodePool.ID()]; ok {
+			value, err := marshalCSNodePool(csNodePool, doc, versionedInterface)
+			if err != nil {
+				return nil, err
+			}
+			pagedResponse.AddValue(value)
+		}
+	}
+
+	err = csIterator.GetError()
+	if err != nil {
+		return nil, err
+	}
+
+	continuationToken := dbIterator.GetContinuationToken()
+	if continuationToken != "" {
+		nextLink, err := nodePoolCollectionURL(baseURL, versionedInterface)
+		if err != nil {
+			return nil, err
+		}
+		err = pagedResponse.SetNextLink(nextLink, continuationToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pagedResponse, nil
+}
+
+// nodePoolCollectionURL converts a cluster's request URL to the
+// URL of the cluster's node pool collection.
+func nodePoolCollectionURL(clusterURL string, versionedInterface api.Version) (string, error) {
+	u, err := url.ParseRequestURI(clusterURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = path.Join(u.Path, api.NodePoolResourceTypeName)
+	u.RawQuery = url.Values{APIVersionKey: []string{versionedInterface.String()}}.Encode()
+
+	return u.String(), nil
+}
+
+// embedExpansion adds an expanded collection to a marshaled resource
+// under the given property name.
+func embedExpansion(responseBody []byte, name string, expansion *arm.PagedResponse) ([]byte, error) {
+	var resource map[string]json.RawMessage
+
+	err := json.Unmarshal(responseBody, &resource)
+	if err != nil {
+		return nil, err
+	}
+
+	resource[name], err = json.Marshal(expansion)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resource)
+}
@@ -0,0 +1,173 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		name           string
+		value          string
+		resourceType   string
+		expectedExpand bool
+		expectError    bool
+	}{
+		{
+			name:           "No expansion",
+			value:          "",
+			resourceType:   api.ClusterResourceType.String(),
+			expectedExpand: false,
+		},
+		{
+			name:           "Cluster node pools",
+			value:          "nodePools",
+			resourceType:   api.ClusterResourceType.String(),
+			expectedExpand: true,
+		},
+		{
+			name:           "Case-insensitive",
+			value:          " NODEPOOLS ",
+			resourceType:   "microsoft.redhatopenshift/hcpopenshiftclusters",
+			expectedExpand: true,
+		},
+		{
+			name:         "Unknown expansion",
+			value:        "nodePools,operations",
+			resourceType: api.ClusterResourceType.String(),
+			expectError:  true,
+		},
+		{
+			name:         "Node pool has nothing to expand",
+			value:        "nodePools",
+			resourceType: api.NodePoolResourceType.String(),
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expand, err := parseExpand(tt.value, tt.resourceType)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expand != tt.expectedExpand {
+				t.Errorf("expected %t, got %t", tt.expectedExpand, expand)
+			}
+		})
+	}
+}
+
+func TestExpandNodePools(t *testing.T) {
+	ctx := ContextWithLogger(context.Background(), testLogger)
+
+	versionedInterface, ok := api.Lookup("2024-06-10-preview")
+	if !ok {
+		t.Fatal("API version not found")
+	}
+
+	mockCSClient := ocm.NewMockClusterServiceClient()
+	f := &Frontend{
+		dbClient:             database.NewCache(),
+		clusterServiceClient: &mockCSClient,
+	}
+
+	clusterResourceID, err := arm.ParseResourceID(dummyClusterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterDoc := database.NewResourceDocument(clusterResourceID)
+	clusterDoc.InternalID, err = ocm.NewInternalID(dummyClusterHREF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.dbClient.CreateResourceDoc(ctx, clusterDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodePoolNames := []string{"nodepool1", "nodepool2"}
+	for _, name := range nodePoolNames {
+		nodePoolResourceID, err := arm.ParseResourceID(dummyClusterID + "/nodePools/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodePoolDoc := database.NewResourceDocument(nodePoolResourceID)
+		nodePoolDoc.InternalID, err = ocm.NewInternalID(ocm.GenerateNodePoolHREF(dummyClusterHREF, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = f.dbClient.CreateResourceDoc(ctx, nodePoolDoc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		csNodePool, err := cmv1.NewNodePool().ID(name).Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = f.clusterServiceClient.PostCSNodePool(ctx, clusterDoc.InternalID, csNodePool)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nodePools, err := f.expandNodePools(ctx, clusterDoc, versionedInterface, "https://example.com"+dummyClusterID+"?api-version=2024-06-10-preview&$expand=nodePools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodePools.Value) != len(nodePoolNames) {
+		t.Errorf("expected %d node pools, got %d", len(nodePoolNames), len(nodePools.Value))
+	}
+	if nodePools.NextLink != "" {
+		t.Errorf("expected no next link, got '%s'", nodePools.NextLink)
+	}
+
+	responseBody, err := embedExpansion([]byte(`{"name":"`+dummyClusterName+`"}`), ExpandNodePools, nodePools)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resource struct {
+		Name      string            `json:"name"`
+		NodePools arm.PagedResponse `json:"nodePools"`
+	}
+	err = json.Unmarshal(responseBody, &resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resource.Name != dummyClusterName {
+		t.Errorf("expected name '%s', got '%s'", dummyClusterName, resource.Name)
+	}
+	if len(resource.NodePools.Value) != len(nodePoolNames) {
+		t.Errorf("expected %d embedded node pools, got %d", len(nodePoolNames), len(resource.NodePools.Value))
+	}
+}
+
+func TestNodePoolCollectionURL(t *testing.T) {
+	versionedInterface, ok := api.Lookup("2024-06-10-preview")
+	if !ok {
+		t.Fatal("API version not found")
+	}
+
+	nodePoolsURL, err := nodePoolCollectionURL("https://example.com"+dummyClusterID+"?api-version=2024-06-10-preview&%24expand=nodePools", versionedInterface)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "https://example.com" + dummyClusterID + "/nodePools?api-version=2024-06-10-preview"
+	if nodePoolsURL != expected {
+		t.Errorf("expected '%s', got '%s'", expected, nodePoolsURL)
+	}
+}
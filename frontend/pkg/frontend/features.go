@@ -0,0 +1,120 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+// SubscriptionFeaturesPathSegment is the trailing path segment of the
+// endpoint that lists the features registered for a subscription.
+const SubscriptionFeaturesPathSegment = "features"
+
+// subscriptionFeature is a single registered feature and its state.
+type subscriptionFeature struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// subscriptionFeaturesResponse is the response body of a request for the
+// features registered for a subscription.
+type subscriptionFeaturesResponse struct {
+	Value []subscriptionFeature `json:"value"`
+}
+
+// newSubscriptionFeaturesResponse builds a response from the registered
+// features of a subscription, sorted by feature name. Features with a
+// missing name or state are omitted.
+func newSubscriptionFeaturesResponse(subscription *arm.Subscription) subscriptionFeaturesResponse {
+	response := subscriptionFeaturesResponse{Value: []subscriptionFeature{}}
+
+	if subscription == nil || subscription.Properties == nil {
+		return response
+	}
+
+	features := featuresMap(subscription.Properties.RegisteredFeatures)
+	for _, name := range slices.Sorted(maps.Keys(features)) {
+		response.Value = append(response.Value, subscriptionFeature{
+			Name:  name,
+			State: features[name],
+		})
+	}
+
+	return response
+}
+
+// SubscriptionFeaturesGet returns the features registered for the
+// subscription in the request path, as last reported by ARM. Requests
+// from a tenant other than the one owning the subscription are answered
+// as though the subscription does not exist.
+func (f *Frontend) SubscriptionFeaturesGet(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	logger := LoggerFromContext(ctx)
+
+	subscriptionID := request.PathValue(PathSegmentSubscriptionID)
+
+	doc, err := f.dbClient.GetSubscriptionDoc(ctx, subscriptionID)
+	if err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, database.ErrNotFound) {
+			writeSubscriptionNotFoundError(writer, subscriptionID)
+		} else {
+			arm.WriteInternalServerError(writer)
+		}
+		return
+	}
+
+	tenantID := request.Header.Get(arm.HeaderNameHomeTenantID)
+	if tenantID != "" &&
+		doc.Subscription != nil &&
+		doc.Subscription.Properties != nil &&
+		doc.Subscription.Properties.TenantId != nil &&
+		*doc.Subscription.Properties.TenantId != tenantID {
+		logger.Warn(fmt.Sprintf("Rejected features request for subscription '%s' from tenant '%s'", subscriptionID, tenantID))
+		writeSubscriptionNotFoundError(writer, subscriptionID)
+		return
+	}
+
+	_, err = arm.WriteJSONResponse(writer, http.StatusOK, newSubscriptionFeaturesResponse(doc.Subscription))
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// checkSubscriptionFeatures compares the features registered for a
+// subscription against the known features. ARM's subscription notification
+// must never be rejected, or the resource provider's view of the
+// subscription would drift from ARM's, so unknown features are only logged
+// and counted.
+func (f *Frontend) checkSubscriptionFeatures(ctx context.Context, subscriptionID string, subscription *arm.Subscription) {
+	unknownFeatures := api.UnknownSubscriptionFeatures(subscription, f.knownSubscriptionFeatures)
+	if len(unknownFeatures) == 0 {
+		return
+	}
+
+	LoggerFromContext(ctx).Warn(fmt.Sprintf("Unrecognized features for subscription '%s': %s", subscriptionID, strings.Join(unknownFeatures, ", ")))
+
+	for _, feature := range unknownFeatures {
+		f.metrics.EmitCounter("frontend_unknown_subscription_feature", 1, map[string]string{
+			"location": f.location,
+			"feature":  feature,
+		})
+	}
+}
+
+func writeSubscriptionNotFoundError(writer http.ResponseWriter, subscriptionID string) {
+	arm.WriteError(writer, http.StatusNotFound,
+		arm.CloudErrorCodeSubscriptionNotFound, "",
+		"The subscription '%s' could not be found.", subscriptionID)
+}
@@ -0,0 +1,184 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+func TestSubscriptionFeaturesGet(t *testing.T) {
+	const (
+		subscriptionID = "00000000-0000-0000-0000-000000000000"
+		ownerTenantID  = "00000000-0000-0000-0000-00000000000a"
+		otherTenantID  = "00000000-0000-0000-0000-00000000000b"
+	)
+
+	tests := []struct {
+		name               string
+		docExists          bool
+		features           *[]arm.Feature
+		tenantID           string
+		expectedStatusCode int
+		expectedFeatures   []subscriptionFeature
+	}{
+		{
+			name:               "Missing subscription document",
+			docExists:          false,
+			tenantID:           ownerTenantID,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "No registered features",
+			docExists:          true,
+			tenantID:           ownerTenantID,
+			expectedStatusCode: http.StatusOK,
+			expectedFeatures:   []subscriptionFeature{},
+		},
+		{
+			name:      "Registered features are sorted by name",
+			docExists: true,
+			features: &[]arm.Feature{
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureB"), State: api.Ptr("Registered")},
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureA"), State: api.Ptr("Pending")},
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/Incomplete")},
+			},
+			tenantID:           ownerTenantID,
+			expectedStatusCode: http.StatusOK,
+			expectedFeatures: []subscriptionFeature{
+				{Name: "Microsoft.RedHatOpenShift/FeatureA", State: "Pending"},
+				{Name: "Microsoft.RedHatOpenShift/FeatureB", State: "Registered"},
+			},
+		},
+		{
+			name:      "Request from another tenant is rejected",
+			docExists: true,
+			features: &[]arm.Feature{
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureA"), State: api.Ptr("Registered")},
+			},
+			tenantID:           otherTenantID,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			f := &Frontend{
+				dbClient: database.NewCache(),
+			}
+
+			if tt.docExists {
+				doc := database.NewSubscriptionDocument(subscriptionID, &arm.Subscription{
+					State: arm.SubscriptionStateRegistered,
+					Properties: &arm.SubscriptionProperties{
+						TenantId:           api.Ptr(ownerTenantID),
+						RegisteredFeatures: tt.features,
+					},
+				})
+				err := f.dbClient.CreateSubscriptionDoc(ctx, doc)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/subscriptions/"+subscriptionID+"/providers/"+api.ProviderNamespace+"/"+SubscriptionFeaturesPathSegment, nil)
+			request = request.WithContext(ContextWithLogger(ctx, testLogger))
+			request.SetPathValue(PathSegmentSubscriptionID, subscriptionID)
+			request.Header.Set(arm.HeaderNameHomeTenantID, tt.tenantID)
+
+			writer := httptest.NewRecorder()
+
+			f.SubscriptionFeaturesGet(writer, request)
+
+			if writer.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, writer.Code)
+			}
+
+			if writer.Code == http.StatusOK {
+				var response subscriptionFeaturesResponse
+				err := json.Unmarshal(writer.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tt.expectedFeatures, response.Value); diff != "" {
+					t.Errorf("unexpected features (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckSubscriptionFeatures(t *testing.T) {
+	const subscriptionID = "00000000-0000-0000-0000-000000000000"
+
+	knownFeatures := []string{
+		"Microsoft.RedHatOpenShift/FeatureA",
+		"Microsoft.RedHatOpenShift/FeatureB",
+	}
+
+	tests := []struct {
+		name            string
+		features        *[]arm.Feature
+		expectedUnknown int
+	}{
+		{
+			name: "Unknown features are counted",
+			features: &[]arm.Feature{
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureA"), State: api.Ptr("Registered")},
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureY"), State: api.Ptr("Registered")},
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureZ"), State: api.Ptr("Registered")},
+			},
+			expectedUnknown: 2,
+		},
+		{
+			name: "Known features are not counted",
+			features: &[]arm.Feature{
+				{Name: api.Ptr("Microsoft.RedHatOpenShift/FeatureB"), State: api.Ptr("Registered")},
+			},
+			expectedUnknown: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := ContextWithLogger(context.Background(), testLogger)
+			registry := prometheus.NewRegistry()
+
+			f := &Frontend{
+				metrics:                   NewPrometheusEmitter(registry),
+				knownSubscriptionFeatures: knownFeatures,
+			}
+
+			subscription := &arm.Subscription{
+				State: arm.SubscriptionStateRegistered,
+				Properties: &arm.SubscriptionProperties{
+					RegisteredFeatures: tt.features,
+				},
+			}
+
+			f.checkSubscriptionFeatures(ctx, subscriptionID, subscription)
+
+			count, err := testutil.GatherAndCount(registry, "frontend_unknown_subscription_feature")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.expectedUnknown {
+				t.Errorf("expected %d unknown features, got %d", tt.expectedUnknown, count)
+			}
+		})
+	}
+}
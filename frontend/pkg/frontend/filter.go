@@ -0,0 +1,79 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+const (
+	filterPropertyProvisioningState = "properties/provisioningstate"
+	filterPropertyTagPrefix         = "tags/"
+)
+
+var (
+	// filterClauseRegexp matches a single "<property> eq '<value>'" clause
+	// at the start of a $filter expression. Single quotes in the value are
+	// escaped by doubling them, per OData conventions.
+	filterClauseRegexp = regexp.MustCompile(`^\s*([^\s']+)\s+(?i:eq)\s+'((?:[^']|'')*)'\s*`)
+
+	// filterAndRegexp matches the conjunction between two clauses.
+	filterAndRegexp = regexp.MustCompile(`^(?i:and)\s+`)
+)
+
+// parseListFilter translates a limited subset of the OData $filter syntax
+// into a database.ResourceDocumentFilter. Only equality comparisons on the
+// provisioning state and on tags, joined by "and", are supported:
+//
+//	properties/provisioningState eq 'Succeeded' and tags/env eq 'prod'
+//
+// A period may be used in place of the slash after "properties". An empty
+// expression returns a nil filter.
+func parseListFilter(expression string) (*database.ResourceDocumentFilter, error) {
+	var filter *database.ResourceDocumentFilter
+
+	rest := strings.TrimSpace(expression)
+	for rest != "" {
+		match := filterClauseRegexp.FindStringSubmatch(rest)
+		if match == nil {
+			return nil, fmt.Errorf("unsupported $filter expression '%s'", rest)
+		}
+		rest = rest[len(match[0]):]
+
+		property := match[1]
+		value := strings.ReplaceAll(match[2], "''", "'")
+
+		if filter == nil {
+			filter = &database.ResourceDocumentFilter{}
+		}
+
+		lowerProperty := strings.ToLower(strings.Replace(property, ".", "/", 1))
+		switch {
+		case lowerProperty == filterPropertyProvisioningState:
+			filter.ProvisioningState = arm.ProvisioningState(value)
+		case strings.HasPrefix(lowerProperty, filterPropertyTagPrefix) && len(property) > len(filterPropertyTagPrefix):
+			if filter.Tags == nil {
+				filter.Tags = make(map[string]string)
+			}
+			filter.Tags[property[len(filterPropertyTagPrefix):]] = value
+		default:
+			return nil, fmt.Errorf("unsupported $filter property '%s'", property)
+		}
+
+		if rest != "" {
+			and := filterAndRegexp.FindString(rest)
+			if and == "" {
+				return nil, fmt.Errorf("unsupported $filter expression '%s'", rest)
+			}
+			rest = rest[len(and):]
+		}
+	}
+
+	return filter, nil
+}
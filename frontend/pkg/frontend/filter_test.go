@@ -0,0 +1,104 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+func TestParseListFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		expression  string
+		expected    *database.ResourceDocumentFilter
+		expectError bool
+	}{
+		{
+			name:       "Empty expression",
+			expression: "",
+			expected:   nil,
+		},
+		{
+			name:       "Provisioning state",
+			expression: "properties/provisioningState eq 'Succeeded'",
+			expected: &database.ResourceDocumentFilter{
+				ProvisioningState: arm.ProvisioningStateSucceeded,
+			},
+		},
+		{
+			name:       "Provisioning state with period separator",
+			expression: "Properties.ProvisioningState EQ 'Failed'",
+			expected: &database.ResourceDocumentFilter{
+				ProvisioningState: arm.ProvisioningStateFailed,
+			},
+		},
+		{
+			name:       "Tag with escaped quote",
+			expression: "tags/owner eq 'O''Brien'",
+			expected: &database.ResourceDocumentFilter{
+				Tags: map[string]string{"owner": "O'Brien"},
+			},
+		},
+		{
+			name:       "Multiple clauses",
+			expression: "tags/env eq 'prod' and properties/provisioningState eq 'Succeeded' AND tags/team eq 'a b'",
+			expected: &database.ResourceDocumentFilter{
+				ProvisioningState: arm.ProvisioningStateSucceeded,
+				Tags:              map[string]string{"env": "prod", "team": "a b"},
+			},
+		},
+		{
+			name:        "Unsupported property",
+			expression:  "name eq 'foo'",
+			expectError: true,
+		},
+		{
+			name:        "Unsupported operator",
+			expression:  "tags/env ne 'prod'",
+			expectError: true,
+		},
+		{
+			name:        "Unsupported conjunction",
+			expression:  "tags/env eq 'prod' or tags/env eq 'dev'",
+			expectError: true,
+		},
+		{
+			name:        "Trailing conjunction",
+			expression:  "tags/env eq 'prod' and ",
+			expectError: true,
+		},
+		{
+			name:        "Missing tag key",
+			expression:  "tags/ eq 'prod'",
+			expectError: true,
+		},
+		{
+			name:        "Unquoted value",
+			expression:  "tags/env eq prod",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseListFilter(tt.expression)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got filter %+v", filter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(filter, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, filter)
+			}
+		})
+	}
+}
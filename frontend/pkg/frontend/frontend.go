@@ -17,6 +17,7 @@ import (
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"time"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	"golang.org/x/sync/errgroup"
@@ -28,19 +29,60 @@ import (
 )
 
 type Frontend struct {
-	clusterServiceClient ocm.ClusterServiceClientSpec
-	listener             net.Listener
-	metricsListener      net.Listener
-	server               http.Server
-	metricsServer        http.Server
-	dbClient             database.DBClient
-	ready                atomic.Value
-	done                 chan struct{}
-	metrics              Emitter
-	location             string
+	clusterServiceClient    ocm.ClusterServiceClientSpec
+	listener                net.Listener
+	metricsListener         net.Listener
+	server                  http.Server
+	metricsServer           http.Server
+	dbClient                database.DBClient
+	ready                   atomic.Value
+	done                    chan struct{}
+	metrics                 Emitter
+	location                string
+	adminClientIDs          []string
+	maxPageSize             int32
+	operationPollLimiter    *operationPollLimiter
+	maxNodePoolVersionSkew  int
+	maxRequestBodySize      int64
+	maxSubscriptionRequests int
+
+	knownSubscriptionFeatures []string
 }
 
-func NewFrontend(logger *slog.Logger, listener net.Listener, metricsListener net.Listener, emitter Emitter, dbClient database.DBClient, location string, csClient ocm.ClusterServiceClientSpec) *Frontend {
+// FrontendOptions holds the tunable settings of a Frontend.
+type FrontendOptions struct {
+	// AdminClientIDs are the client IDs allowed to call admin endpoints.
+	AdminClientIDs []string
+	// MaxPageSize is the maximum number of items returned per page of a
+	// collection.
+	MaxPageSize int32
+	// OperationPollInterval is the minimum interval between polls of the
+	// same operation. Each replica tracks polls separately, so polls spread
+	// across replicas can arrive up to the number of replicas times as often.
+	OperationPollInterval time.Duration
+	// OperationPollThrottle rejects polls arriving sooner than the interval
+	// instead of only advertising it. Like the interval, this is enforced
+	// by each replica separately.
+	OperationPollThrottle bool
+	// MaxNodePoolVersionSkew is the maximum number of minor versions a new
+	// node pool may lag its cluster. A negative value disables the check.
+	MaxNodePoolVersionSkew int
+	// MaxRequestBodySize is the maximum size in bytes of a request body.
+	MaxRequestBodySize int64
+	// MaxSubscriptionRequests is the maximum number of concurrent mutating
+	// requests accepted for a single subscription. Zero disables the limit.
+	MaxSubscriptionRequests int
+	// Tracing wraps the database and Cluster Service clients so each call
+	// is recorded as a span. It should be set when an exporter is configured.
+	Tracing bool
+}
+
+func NewFrontend(logger *slog.Logger, listener net.Listener, metricsListener net.Listener, emitter Emitter, dbClient database.DBClient, location string, csClient ocm.ClusterServiceClientSpec, options FrontendOptions) *Frontend {
+	if options.Tracing {
+		dbClient = newTracingDBClient(dbClient)
+		csClient = newTracingClusterServiceClient(csClient)
+	}
+
 	f := &Frontend{
 		clusterServiceClient: csClient,
 		listener:             listener,
@@ -61,9 +103,17 @@ func NewFrontend(logger *slog.Logger, listener net.Listener, metricsListener net
 				return ContextWithLogger(context.Background(), logger)
 			},
 		},
-		dbClient: dbClient,
-		done:     make(chan struct{}),
-		location: strings.ToLower(location),
+		dbClient:                dbClient,
+		done:                    make(chan struct{}),
+		location:                strings.ToLower(location),
+		adminClientIDs:          options.AdminClientIDs,
+		maxPageSize:             options.MaxPageSize,
+		operationPollLimiter:    newOperationPollLimiter(options.OperationPollInterval, options.OperationPollThrottle),
+		maxNodePoolVersionSkew:  options.MaxNodePoolVersionSkew,
+		maxRequestBodySize:      options.MaxRequestBodySize,
+		maxSubscriptionRequests: options.MaxSubscriptionRequests,
+
+		knownSubscriptionFeatures: api.KnownSubscriptionFeatures,
 	}
 
 	f.server.Handler = f.routes()
@@ -109,9 +159,14 @@ func (f *Frontend) Join() {
 	<-f.done
 }
 
+// CheckReady verifies the frontend is serving and that its dependencies
+// are reachable. Each dependency check is bounded by healthCheckTimeout.
 func (f *Frontend) CheckReady(ctx context.Context) bool {
 	logger := LoggerFromContext(ctx)
 
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
 	// Verify the DB is available and accessible
 	if err := f.dbClient.DBConnectionTest(ctx); err != nil {
 		logger.Error(fmt.Sprintf("Database test failed: %v", err))
@@ -119,6 +174,13 @@ func (f *Frontend) CheckReady(ctx context.Context) bool {
 	}
 	logger.Debug("Database check completed")
 
+	// Verify Cluster Service is available and accessible
+	if err := f.clusterServiceClient.CSConnectionTest(ctx); err != nil {
+		logger.Error(fmt.Sprintf("Cluster Service test failed: %v", err))
+		return false
+	}
+	logger.Debug("Cluster Service check completed")
+
 	return f.ready.Load().(bool)
 }
 
@@ -136,7 +198,7 @@ func (f *Frontend) Healthz(writer http.ResponseWriter, request *http.Request) {
 		writer.WriteHeader(http.StatusOK)
 		healthStatus = 1.0
 	} else {
-		arm.WriteInternalServerError(writer)
+		writer.WriteHeader(http.StatusServiceUnavailable)
 		healthStatus = 0.0
 	}
 
@@ -172,9 +234,15 @@ func (f *Frontend) ArmResourceList(writer http.ResponseWriter, request *http.Req
 		}
 	}
 
-	// FIXME We may want to cap pageSizeHint. If we get a large enough
-	//       $top argument (and there's enough actual clusters to reach
-	//       that), we could potentially hit the 8MB response size limit.
+	// Cap pageSizeHint so a large $top argument cannot push
+	// the response body past ARM's 8MB response size limit.
+	pageSizeHint = min(pageSizeHint, f.getMaxPageSize())
+
+	filter, err := parseListFilter(urlQuery.Get("$filter"))
+	if err != nil {
+		arm.WriteError(writer, http.StatusBadRequest, arm.CloudErrorCodeInvalidParameter, "$filter", "Invalid $filter query parameter: %s", err)
+		return
+	}
 
 	subscriptionID := request.PathValue(PathSegmentSubscriptionID)
 	resourceGroupName := request.PathValue(PathSegmentResourceGroupName)
@@ -204,7 +272,7 @@ func (f *Frontend) ArmResourceList(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
-	dbIterator := f.dbClient.ListResourceDocs(ctx, prefix, pageSizeHint, continuationToken)
+	dbIterator := f.dbClient.ListResourceDocs(ctx, prefix, filter, pageSizeHint, continuationToken)
 
 	// Build a map of cluster documents by Cluster Service cluster ID.
 	documentMap := make(map[string]*database.ResourceDocument)
@@ -228,7 +296,8 @@ func (f *Frontend) ArmResourceList(writer http.ResponseWriter, request *http.Req
 	err = dbIterator.GetError()
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
+		return
 	}
 
 	// Build a Cluster Service query that looks for
@@ -264,7 +333,7 @@ func (f *Frontend) ArmResourceList(writer http.ResponseWriter, request *http.Req
 		resourceDoc, err = f.dbClient.GetResourceDoc(ctx, prefix)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 
@@ -310,6 +379,11 @@ func (f *Frontend) ArmResourceList(writer http.ResponseWriter, request *http.Req
 // ArmResourceRead implements the GET single resource API contract for ARM
 // * 200 If the resource exists
 // * 404 If the resource does not exist
+//
+// A cluster GET with $expand=nodePools also embeds up to MaxExpandedNodePools
+// of the cluster's node pools in the response. Any remaining node pools must
+// be listed through the node pool collection, starting from the next link of
+// the embedded page.
 func (f *Frontend) ArmResourceRead(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	logger := LoggerFromContext(ctx)
@@ -328,12 +402,59 @@ func (f *Frontend) ArmResourceRead(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
-	responseBody, cloudError := f.MarshalResource(ctx, resourceID, versionedInterface)
+	expandNodePools, err := parseExpand(request.URL.Query().Get(ExpandKey), resourceID.ResourceType.String())
+	if err != nil {
+		arm.WriteError(writer, http.StatusBadRequest, arm.CloudErrorCodeInvalidParameter, ExpandKey, "Invalid %s query parameter: %s", ExpandKey, err)
+		return
+	}
+
+	doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
+	if err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, database.ErrNotFound) {
+			arm.WriteResourceNotFoundError(writer, resourceID)
+		} else {
+			writeDatabaseError(writer, err)
+		}
+		return
+	}
+
+	// A matching "If-None-Match" header on a GET request means
+	// the client's copy of the resource is still current.
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" && matchEntityTag(ifNoneMatch, EntityTag(doc)) {
+		AddETagHeader(writer, doc)
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if ifMatch := request.Header.Get("If-Match"); ifMatch != "" && !matchEntityTag(ifMatch, EntityTag(doc)) {
+		arm.WriteCloudError(writer, arm.NewPreconditionFailedError(resourceID, "If-Match"))
+		return
+	}
+
+	responseBody, cloudError := f.MarshalResourceDoc(ctx, doc, versionedInterface)
 	if cloudError != nil {
 		arm.WriteCloudError(writer, cloudError)
 		return
 	}
 
+	if expandNodePools {
+		nodePools, err := f.expandNodePools(ctx, doc, versionedInterface, request.Referer())
+		if err != nil {
+			logger.Error(err.Error())
+			writeDatabaseError(writer, err)
+			return
+		}
+		responseBody, err = embedExpansion(responseBody, ExpandNodePools, nodePools)
+		if err != nil {
+			logger.Error(err.Error())
+			arm.WriteInternalServerError(writer)
+			return
+		}
+	}
+
+	AddETagHeader(writer, doc)
+
 	_, err = arm.WriteJSONResponse(writer, http.StatusOK, responseBody)
 	if err != nil {
 		logger.Error(err.Error())
@@ -382,13 +503,23 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 	doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
 	if err != nil && !errors.Is(err, database.ErrNotFound) {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
+		return
+	}
+
+	// Enforce optimistic concurrency for conditional requests.
+	cloudError := CheckPreconditions(request, resourceID, doc)
+	if cloudError != nil {
+		logger.Info(cloudError.Error())
+		arm.WriteCloudError(writer, cloudError)
 		return
 	}
 
 	var updating = (doc != nil)
 	var operationRequest database.OperationRequest
 
+	var currentCSCluster *cmv1.Cluster
+	var currentCluster *api.HCPOpenShiftCluster
 	var versionedCurrentCluster api.VersionedHCPOpenShiftCluster
 	var versionedRequestCluster api.VersionedHCPOpenShiftCluster
 	var successStatusCode int
@@ -400,14 +531,14 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		// No special treatment here for "not found" errors. A "not found"
 		// error indicates the database has gotten out of sync and so it's
 		// appropriate to fail.
-		csCluster, err := f.clusterServiceClient.GetCSCluster(ctx, doc.InternalID)
+		currentCSCluster, err = f.clusterServiceClient.GetCSCluster(ctx, doc.InternalID)
 		if err != nil {
 			logger.Error(fmt.Sprintf("failed to fetch CS cluster for %s: %v", resourceID, err))
 			arm.WriteInternalServerError(writer)
 			return
 		}
 
-		hcpCluster := ConvertCStoHCPOpenShiftCluster(resourceID, csCluster)
+		currentCluster = ConvertCStoHCPOpenShiftCluster(resourceID, currentCSCluster)
 
 		// Do not set the TrackedResource.Tags field here. We need
 		// the Tags map to remain nil so we can see if the request
@@ -418,12 +549,12 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		// This is slightly repetitive for the sake of clarity on PUT vs PATCH.
 		switch request.Method {
 		case http.MethodPut:
-			versionedCurrentCluster = versionedInterface.NewHCPOpenShiftCluster(hcpCluster)
+			versionedCurrentCluster = versionedInterface.NewHCPOpenShiftCluster(currentCluster)
 			versionedRequestCluster = versionedInterface.NewHCPOpenShiftCluster(nil)
 			successStatusCode = http.StatusOK
 		case http.MethodPatch:
-			versionedCurrentCluster = versionedInterface.NewHCPOpenShiftCluster(hcpCluster)
-			versionedRequestCluster = versionedInterface.NewHCPOpenShiftCluster(hcpCluster)
+			versionedCurrentCluster = versionedInterface.NewHCPOpenShiftCluster(currentCluster)
+			versionedRequestCluster = versionedInterface.NewHCPOpenShiftCluster(currentCluster)
 			successStatusCode = http.StatusAccepted
 		}
 	} else {
@@ -444,11 +575,9 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		doc = database.NewResourceDocument(resourceID)
 	}
 
-	// CheckForProvisioningStateConflict does not log conflict errors
+	// RejectProvisioningStateConflict does not log conflict errors
 	// but does log unexpected errors like database failures.
-	cloudError := f.CheckForProvisioningStateConflict(ctx, operationRequest, doc)
-	if cloudError != nil {
-		arm.WriteCloudError(writer, cloudError)
+	if f.RejectProvisioningStateConflict(ctx, writer, operationRequest, doc) {
 		return
 	}
 
@@ -475,6 +604,51 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 	versionedRequestCluster.Normalize(hcpCluster)
 
 	hcpCluster.Name = request.PathValue(PathSegmentResourceName)
+	f.AddDebugInternalClusterHeader(writer, request, hcpCluster)
+
+	// Network settings cannot be updated, so only warn about them on create.
+	if !updating {
+		for _, warning := range api.ValidateClusterWarnings(hcpCluster) {
+			logger.Info(fmt.Sprintf("Validation warning: %s", warning))
+			writer.Header().Add(HeaderNameValidationWarning, warning.String())
+		}
+	}
+
+	var specDifferences []string
+	if updating {
+		// The current tags are only needed now, to compare against
+		// the request. Cluster Service does not store resource tags.
+		currentCluster.TrackedResource.Tags = doc.Tags
+
+		differences := api.ClusterDifferences(currentCluster, hcpCluster)
+		for _, message := range differences {
+			logger.Info(message)
+		}
+		specDifferences = api.ClusterSpecDifferences(currentCluster, hcpCluster)
+
+		// A PATCH request that changes nothing would only create
+		// an operation with nothing to do, so return the resource
+		// as it is.
+		if len(differences) == 0 && request.Method == http.MethodPatch {
+			logger.Info(fmt.Sprintf("no changes to resource %s", resourceID))
+
+			responseBody, err := marshalCSCluster(currentCSCluster, doc, versionedInterface)
+			if err != nil {
+				logger.Error(err.Error())
+				arm.WriteInternalServerError(writer)
+				return
+			}
+
+			AddETagHeader(writer, doc)
+
+			_, err = arm.WriteJSONResponse(writer, http.StatusOK, responseBody)
+			if err != nil {
+				logger.Error(err.Error())
+			}
+			return
+		}
+	}
+
 	csCluster, err := f.BuildCSCluster(resourceID, request.Header, hcpCluster, updating)
 	if err != nil {
 		logger.Error(err.Error())
@@ -483,19 +657,26 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 	}
 
 	if updating {
-		logger.Info(fmt.Sprintf("updating resource %s", resourceID))
-		csCluster, err = f.clusterServiceClient.UpdateCSCluster(ctx, doc.InternalID, csCluster)
-		if err != nil {
-			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
-			return
+		// Cluster Service does not store resource tags, so only
+		// spec changes need to be sent to it.
+		if len(specDifferences) == 0 {
+			logger.Info(fmt.Sprintf("no changes to resource %s in Cluster Service", resourceID))
+			csCluster = currentCSCluster
+		} else {
+			logger.Info(fmt.Sprintf("updating resource %s", resourceID))
+			csCluster, err = f.clusterServiceClient.UpdateCSCluster(ctx, doc.InternalID, csCluster)
+			if err != nil {
+				logger.Error(err.Error())
+				arm.WriteCloudError(writer, CSErrorToCloudError(err, csClusterAttributeTargets))
+				return
+			}
 		}
 	} else {
 		logger.Info(fmt.Sprintf("creating resource %s", resourceID))
 		csCluster, err = f.clusterServiceClient.PostCSCluster(ctx, csCluster)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			arm.WriteCloudError(writer, CSErrorToCloudError(err, csClusterAttributeTargets))
 			return
 		}
 
@@ -507,19 +688,19 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		}
 	}
 
-	operationDoc := database.NewOperationDocument(operationRequest, doc.Key, doc.InternalID)
+	operationDoc := newOperationDocument(ctx, operationRequest, doc.Key, doc.InternalID)
 
 	err = f.dbClient.CreateOperationDoc(ctx, operationDoc)
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
 	err = f.ExposeOperation(writer, request, operationDoc.ID)
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
@@ -551,15 +732,22 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		err = f.dbClient.CreateResourceDoc(ctx, doc)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 		logger.Info(fmt.Sprintf("document created for %s", resourceID))
 	} else {
-		updated, err := f.dbClient.UpdateResourceDoc(ctx, resourceID, updateResourceMetadata)
+		// A conditional request must not overwrite a change made since
+		// its preconditions were checked.
+		updated, cloudError, err := f.UpdateConditionalResourceDoc(ctx, request, doc, updateResourceMetadata)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
+			return
+		}
+		if cloudError != nil {
+			logger.Info(cloudError.Error())
+			arm.WriteCloudError(writer, cloudError)
 			return
 		}
 		if updated {
@@ -569,7 +757,7 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		doc, err = f.dbClient.GetResourceDoc(ctx, resourceID)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 	}
@@ -581,6 +769,8 @@ func (f *Frontend) ArmResourceCreateOrUpdate(writer http.ResponseWriter, request
 		return
 	}
 
+	AddETagHeader(writer, doc)
+
 	_, err = arm.WriteJSONResponse(writer, successStatusCode, responseBody)
 	if err != nil {
 		logger.Error(err.Error())
@@ -612,19 +802,25 @@ func (f *Frontend) ArmResourceDelete(writer http.ResponseWriter, request *http.R
 			writer.WriteHeader(http.StatusNoContent)
 		} else {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 		}
 		return
 	}
 
-	// CheckForProvisioningStateConflict does not log conflict errors
-	// but does log unexpected errors like database failures.
-	cloudError := f.CheckForProvisioningStateConflict(ctx, operationRequest, resourceDoc)
+	// Enforce optimistic concurrency for conditional requests.
+	cloudError := CheckPreconditions(request, resourceID, resourceDoc)
 	if cloudError != nil {
+		logger.Info(cloudError.Error())
 		arm.WriteCloudError(writer, cloudError)
 		return
 	}
 
+	// RejectProvisioningStateConflict does not log conflict errors
+	// but does log unexpected errors like database failures.
+	if f.RejectProvisioningStateConflict(ctx, writer, operationRequest, resourceDoc) {
+		return
+	}
+
 	operationID, cloudError := f.DeleteResource(ctx, resourceDoc)
 	if cloudError != nil {
 		// For resource not found errors on deletion, ARM requires
@@ -640,7 +836,7 @@ func (f *Frontend) ArmResourceDelete(writer http.ResponseWriter, request *http.R
 	err = f.ExposeOperation(writer, request, operationID)
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
@@ -670,7 +866,7 @@ func (f *Frontend) ArmSubscriptionGet(writer http.ResponseWriter, request *http.
 		if errors.Is(err, database.ErrNotFound) {
 			arm.WriteResourceNotFoundError(writer, resourceID)
 		} else {
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 		}
 		return
 	}
@@ -709,19 +905,21 @@ func (f *Frontend) ArmSubscriptionPut(writer http.ResponseWriter, request *http.
 
 	subscriptionID := request.PathValue(PathSegmentSubscriptionID)
 
+	f.checkSubscriptionFeatures(ctx, subscriptionID, &subscription)
+
 	_, err = f.dbClient.GetSubscriptionDoc(ctx, subscriptionID)
 	if errors.Is(err, database.ErrNotFound) {
 		doc := database.NewSubscriptionDocument(subscriptionID, &subscription)
 		err = f.dbClient.CreateSubscriptionDoc(ctx, doc)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 		logger.Info(fmt.Sprintf("created document for subscription %s", subscriptionID))
 	} else if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	} else {
 		updated, err := f.dbClient.UpdateSubscriptionDoc(ctx, subscriptionID, func(doc *database.SubscriptionDocument) bool {
@@ -736,7 +934,7 @@ func (f *Frontend) ArmSubscriptionPut(writer http.ResponseWriter, request *http.
 		})
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 		if updated {
@@ -878,6 +1076,63 @@ func (f *Frontend) ArmDeploymentPreflight(writer http.ResponseWriter, request *h
 	arm.WriteDeploymentPreflightResponse(writer, preflightErrors)
 }
 
+// ArmValidateMoveResources answers ARM's validation request before moving
+// resources to another resource group or subscription. HCP clusters and
+// their node pools are bound to managed resources in their original
+// resource group, so moving them is never allowed.
+func (f *Frontend) ArmValidateMoveResources(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	logger := LoggerFromContext(ctx)
+
+	body, err := BodyFromContext(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	moveResources, cloudError := arm.UnmarshalMoveResources(body)
+	if cloudError != nil {
+		logger.Error(cloudError.Error())
+		arm.WriteCloudError(writer, cloudError)
+		return
+	}
+
+	logger.Info(fmt.Sprintf("Validating move of %d resources to '%s'", len(moveResources.Resources), moveResources.TargetResourceGroup))
+
+	var moveErrors []arm.CloudErrorBody
+
+	for _, resource := range moveResources.Resources {
+		resourceID, err := arm.ParseResourceID(resource)
+		if err != nil {
+			arm.WriteInvalidRequestContentError(writer, err)
+			return
+		}
+
+		switch {
+		case strings.EqualFold(resourceID.ResourceType.String(), api.ClusterResourceType.String()),
+			strings.EqualFold(resourceID.ResourceType.String(), api.NodePoolResourceType.String()):
+			moveErrors = append(moveErrors, arm.CloudErrorBody{
+				Code:    arm.CloudErrorCodeResourceMoveNotSupported,
+				Message: fmt.Sprintf("Resources of type '%s' cannot be moved", resourceID.ResourceType),
+				Target:  resourceID.String(),
+			})
+		}
+	}
+
+	if len(moveErrors) > 0 {
+		cloudError = arm.NewCloudError(
+			http.StatusConflict,
+			arm.CloudErrorCodeResourceMoveNotSupported, "",
+			"Resource move is not supported for %d of the requested resources", len(moveErrors))
+		cloudError.Details = moveErrors
+		arm.WriteCloudError(writer, cloudError)
+		return
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+}
+
 func (f *Frontend) OperationStatus(writer http.ResponseWriter, request *http.Request) {
 	ctx := request.Context()
 	logger := LoggerFromContext(ctx)
@@ -907,6 +1162,10 @@ func (f *Frontend) OperationStatus(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
+	if !f.CheckOperationPollInterval(writer, resourceID, doc) {
+		return
+	}
+
 	_, err = arm.WriteJSONResponse(writer, http.StatusOK, doc.ToStatus())
 	if err != nil {
 		logger.Error(err.Error())
@@ -996,6 +1255,10 @@ func (f *Frontend) OperationResult(writer http.ResponseWriter, request *http.Req
 		return
 	}
 
+	if !f.CheckOperationPollInterval(writer, resourceID, doc) {
+		return
+	}
+
 	if !doc.Status.IsTerminal() {
 		f.AddLocationHeader(writer, request, doc)
 		writer.WriteHeader(http.StatusAccepted)
@@ -8,8 +8,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
@@ -17,12 +21,149 @@ import (
 	"github.com/Azure/ARO-HCP/internal/api"
 	"github.com/Azure/ARO-HCP/internal/api/arm"
 	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+// EntityTag returns a quoted HTTP entity tag for the resource document,
+// derived from the Cosmos DB "_etag" metadata. Returns an empty string
+// if the document has no entity tag, such as when using the in-memory
+// cache.
+func EntityTag(doc *database.ResourceDocument) string {
+	if doc == nil || doc.ETag == "" {
+		return ""
+	}
+	etag := string(doc.ETag)
+	if !strings.HasPrefix(etag, `"`) {
+		etag = `"` + etag + `"`
+	}
+	return etag
+}
+
+// AddETagHeader adds an "ETag" header to the ResponseWriter for the given
+// ResourceDocument, if the document has an entity tag.
+func AddETagHeader(writer http.ResponseWriter, doc *database.ResourceDocument) {
+	if etag := EntityTag(doc); etag != "" {
+		writer.Header().Set("ETag", etag)
+	}
+}
+
+// matchEntityTag returns true if the comma-separated list of entity tags
+// from a conditional request header includes the given entity tag or is
+// the wildcard "*". Weak entity tags are compared as if they were strong.
+func matchEntityTag(headerValue, etag string) bool {
+	for _, item := range strings.Split(headerValue, ",") {
+		item = strings.TrimPrefix(strings.TrimSpace(item), "W/")
+		if item == "*" || (etag != "" && item == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPreconditions evaluates the "If-Match" and "If-None-Match" headers
+// of a conditional request against the given ResourceDocument, which is
+// nil if the resource does not yet exist. Returns a "412 Precondition
+// Failed" error response if either precondition is not met.
+//
+// The check does not write the document. A conditional request that goes
+// on to change the resource must do so with UpdateConditionalResourceDoc,
+// so that a change made since the check is not overwritten.
+func CheckPreconditions(request *http.Request, resourceID *arm.ResourceID, doc *database.ResourceDocument) *arm.CloudError {
+	if ifMatch := request.Header.Get("If-Match"); ifMatch != "" {
+		if doc == nil || !matchEntityTag(ifMatch, EntityTag(doc)) {
+			return arm.NewPreconditionFailedError(resourceID, "If-Match")
+		}
+	}
+
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if doc != nil && matchEntityTag(ifNoneMatch, EntityTag(doc)) {
+			return arm.NewPreconditionFailedError(resourceID, "If-None-Match")
+		}
+	}
+
+	return nil
+}
+
+// conditionalHeader returns the name of the conditional request header
+// present in the request, or an empty string if there is none.
+func conditionalHeader(request *http.Request) string {
+	for _, header := range []string{"If-Match", "If-None-Match"} {
+		if request.Header.Get(header) != "" {
+			return header
+		}
+	}
+	return ""
+}
+
+// UpdateConditionalResourceDoc updates the given ResourceDocument through
+// DBClient.UpdateResourceDoc on behalf of a request that may be conditional.
+//
+// For a conditional request, the callback only sees a document whose entity
+// tag is still that of the given document, which the request's preconditions
+// were evaluated against. UpdateResourceDoc conditions its replace on the
+// entity tag of the document it passes to the callback, so the write is in
+// effect conditioned on the client's entity tag. Returns a "412 Precondition
+// Failed" error response if the document has changed since it was read.
+func (f *Frontend) UpdateConditionalResourceDoc(ctx context.Context, request *http.Request, doc *database.ResourceDocument, callback func(*database.ResourceDocument) bool) (bool, *arm.CloudError, error) {
+	header := conditionalHeader(request)
+	if header == "" {
+		updated, err := f.dbClient.UpdateResourceDoc(ctx, doc.Key, callback)
+		return updated, nil, err
+	}
+
+	etag := doc.ETag
+	var changed bool
+
+	updated, err := f.dbClient.UpdateResourceDoc(ctx, doc.Key, func(updateDoc *database.ResourceDocument) bool {
+		if updateDoc.ETag != etag {
+			changed = true
+			return false
+		}
+		return callback(updateDoc)
+	})
+	if err == nil && changed {
+		return false, arm.NewPreconditionFailedError(doc.Key, header), nil
+	}
+	return updated, nil, err
+}
+
+const (
+	// Bounds for the "Retry-After" header on provisioning state conflicts.
+	conflictRetryAfterMin = 10 * time.Second
+	conflictRetryAfterMax = 60 * time.Second
 )
 
 // CheckForProvisioningStateConflict returns a "409 Conflict" error response if the
 // provisioning state of the resource is non-terminal, or any of its parent resources
 // within the same provider namespace are in a "Deleting" state.
 func (f *Frontend) CheckForProvisioningStateConflict(ctx context.Context, operationRequest database.OperationRequest, doc *database.ResourceDocument) *arm.CloudError {
+	cloudError, _ := f.findProvisioningStateConflict(ctx, operationRequest, doc)
+	return cloudError
+}
+
+// RejectProvisioningStateConflict writes an error response and returns true if
+// CheckForProvisioningStateConflict finds a conflict. Conflict responses include
+// a "Retry-After" header derived from how long the conflicting operation has been
+// running, so clients back off further the longer an operation takes.
+func (f *Frontend) RejectProvisioningStateConflict(ctx context.Context, writer http.ResponseWriter, operationRequest database.OperationRequest, doc *database.ResourceDocument) bool {
+	cloudError, conflictDoc := f.findProvisioningStateConflict(ctx, operationRequest, doc)
+	if cloudError == nil {
+		return false
+	}
+
+	if conflictDoc != nil {
+		retryAfter := f.conflictRetryAfter(ctx, conflictDoc)
+		writer.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	}
+
+	arm.WriteCloudError(writer, cloudError)
+	return true
+}
+
+// findProvisioningStateConflict implements CheckForProvisioningStateConflict.
+// If there is a conflict, it also returns the ResourceDocument whose state is
+// in conflict, which is either the given document or one of its parents.
+func (f *Frontend) findProvisioningStateConflict(ctx context.Context, operationRequest database.OperationRequest, doc *database.ResourceDocument) (*arm.CloudError, *database.ResourceDocument) {
 	logger := LoggerFromContext(ctx)
 
 	switch operationRequest {
@@ -34,7 +175,7 @@ func (f *Frontend) CheckForProvisioningStateConflict(ctx context.Context, operat
 				http.StatusConflict,
 				arm.CloudErrorCodeConflict,
 				doc.Key.String(),
-				"Resource is already deleting")
+				"Resource is already deleting"), doc
 		}
 	case database.OperationRequestUpdate:
 		if !doc.ProvisioningState.IsTerminal() {
@@ -43,7 +184,7 @@ func (f *Frontend) CheckForProvisioningStateConflict(ctx context.Context, operat
 				arm.CloudErrorCodeConflict,
 				doc.Key.String(),
 				"Cannot update resource while resource is %s",
-				strings.ToLower(string(doc.ProvisioningState)))
+				strings.ToLower(string(doc.ProvisioningState))), doc
 		}
 	}
 
@@ -54,7 +195,7 @@ func (f *Frontend) CheckForProvisioningStateConflict(ctx context.Context, operat
 		parentDoc, err := f.dbClient.GetResourceDoc(ctx, parent)
 		if err != nil {
 			logger.Error(err.Error())
-			return arm.NewInternalServerError()
+			return arm.NewInternalServerError(), nil
 		}
 
 		if parentDoc.ProvisioningState == arm.ProvisioningStateDeleting {
@@ -63,13 +204,39 @@ func (f *Frontend) CheckForProvisioningStateConflict(ctx context.Context, operat
 				arm.CloudErrorCodeConflict,
 				doc.Key.String(),
 				"Cannot %s resource while parent resource is deleting",
-				strings.ToLower(string(operationRequest)))
+				strings.ToLower(string(operationRequest))), parentDoc
 		}
 
 		parent = parent.GetParent()
 	}
 
-	return nil
+	return nil, nil
+}
+
+// conflictRetryAfter returns how long a client should wait before retrying
+// a request that conflicted with the active operation on the given document.
+// The hint doubles from conflictRetryAfterMin for as long as the operation
+// has been running, up to conflictRetryAfterMax.
+func (f *Frontend) conflictRetryAfter(ctx context.Context, doc *database.ResourceDocument) time.Duration {
+	retryAfter := conflictRetryAfterMin
+
+	if doc.ActiveOperationID == "" {
+		return retryAfter
+	}
+
+	operationDoc, err := f.dbClient.GetOperationDoc(ctx, doc.ActiveOperationID)
+	if err != nil {
+		// Not worth failing the request over; fall back to the minimum.
+		LoggerFromContext(ctx).Warn(err.Error())
+		return retryAfter
+	}
+
+	elapsed := time.Since(operationDoc.StartTime)
+	for retryAfter*2 <= elapsed && retryAfter < conflictRetryAfterMax {
+		retryAfter *= 2
+	}
+
+	return min(retryAfter, conflictRetryAfterMax)
 }
 
 func (f *Frontend) DeleteAllResources(ctx context.Context, subscriptionID string) *arm.CloudError {
@@ -81,7 +248,7 @@ func (f *Frontend) DeleteAllResources(ctx context.Context, subscriptionID string
 		return arm.NewInternalServerError()
 	}
 
-	dbIterator := f.dbClient.ListResourceDocs(ctx, prefix, -1, nil)
+	dbIterator := f.dbClient.ListResourceDocs(ctx, prefix, nil, -1, nil)
 
 	// Start a deletion operation for all clusters under the subscription.
 	// Cluster Service will delete all node pools belonging to these clusters
@@ -157,7 +324,7 @@ func (f *Frontend) DeleteResource(ctx context.Context, resourceDoc *database.Res
 		return "", arm.NewInternalServerError()
 	}
 
-	operationDoc := database.NewOperationDocument(operationRequest, resourceDoc.Key, resourceDoc.InternalID)
+	operationDoc := newOperationDocument(ctx, operationRequest, resourceDoc.Key, resourceDoc.InternalID)
 
 	err = f.dbClient.CreateOperationDoc(ctx, operationDoc)
 	if err != nil {
@@ -175,7 +342,7 @@ func (f *Frontend) DeleteResource(ctx context.Context, resourceDoc *database.Res
 		return "", arm.NewInternalServerError()
 	}
 
-	iterator := f.dbClient.ListResourceDocs(ctx, resourceDoc.Key, -1, nil)
+	iterator := f.dbClient.ListResourceDocs(ctx, resourceDoc.Key, nil, -1, nil)
 
 	for item := range iterator.Items(ctx) {
 		// Anonymous function avoids repetitive error handling.
@@ -196,7 +363,7 @@ func (f *Frontend) DeleteResource(ctx context.Context, resourceDoc *database.Res
 			// Its purpose is to cause the backend to delete the resource
 			// document once resource deletion completes.
 
-			childOperationDoc := database.NewOperationDocument(operationRequest, child.Key, child.InternalID)
+			childOperationDoc := newOperationDocument(ctx, operationRequest, child.Key, child.InternalID)
 
 			err = f.dbClient.CreateOperationDoc(ctx, childOperationDoc)
 			if err != nil {
@@ -229,9 +396,17 @@ func (f *Frontend) DeleteResource(ctx context.Context, resourceDoc *database.Res
 	return operationDoc.ID, nil
 }
 
-func (f *Frontend) MarshalResource(ctx context.Context, resourceID *arm.ResourceID, versionedInterface api.Version) ([]byte, *arm.CloudError) {
-	var responseBody []byte
+// newOperationDocument creates an operation document that carries the
+// correlation data of the request in the context, if any.
+func newOperationDocument(ctx context.Context, request database.OperationRequest, externalID *arm.ResourceID, internalID ocm.InternalID) *database.OperationDocument {
+	doc := database.NewOperationDocument(request, externalID, internalID)
+	if correlationData, err := CorrelationDataFromContext(ctx); err == nil {
+		doc.SetCorrelationData(correlationData)
+	}
+	return doc
+}
 
+func (f *Frontend) MarshalResource(ctx context.Context, resourceID *arm.ResourceID, versionedInterface api.Version) ([]byte, *arm.CloudError) {
 	logger := LoggerFromContext(ctx)
 
 	doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
@@ -244,6 +419,17 @@ func (f *Frontend) MarshalResource(ctx context.Context, resourceID *arm.Resource
 		}
 	}
 
+	return f.MarshalResourceDoc(ctx, doc, versionedInterface)
+}
+
+// MarshalResourceDoc is like MarshalResource but for a ResourceDocument
+// that the caller has already retrieved from the database.
+func (f *Frontend) MarshalResourceDoc(ctx context.Context, doc *database.ResourceDocument, versionedInterface api.Version) ([]byte, *arm.CloudError) {
+	var responseBody []byte
+
+	logger := LoggerFromContext(ctx)
+	resourceID := doc.Key
+
 	switch doc.InternalID.Kind() {
 	case cmv1.ClusterKind:
 		csCluster, err := f.clusterServiceClient.GetCSCluster(ctx, doc.InternalID)
@@ -284,3 +470,64 @@ func (f *Frontend) MarshalResource(ctx context.Context, resourceID *arm.Resource
 
 	return responseBody, nil
 }
+
+// getMaxPageSize returns the largest page size the frontend will honor
+// when listing resources, falling back to DefaultMaxPageSize if unset.
+func (f *Frontend) getMaxPageSize() int32 {
+	if f.maxPageSize > 0 {
+		return f.maxPageSize
+	}
+	return DefaultMaxPageSize
+}
+
+// IsAdminRequest returns true if the client object ID of the request is
+// among the admin client IDs the frontend was configured with. ARM sets
+// the client object ID header so it can be trusted for this purpose.
+func (f *Frontend) IsAdminRequest(request *http.Request) bool {
+	clientID := request.Header.Get(arm.HeaderNameClientObjectID)
+	if clientID == "" {
+		return false
+	}
+	return slices.ContainsFunc(f.adminClientIDs, func(adminClientID string) bool {
+		return strings.EqualFold(adminClientID, clientID)
+	})
+}
+
+// AddDebugInternalClusterHeader echoes the normalized internal representation
+// of the cluster, with sensitive fields redacted, in a response header if the
+// request asks for it and the request originates from an admin client.
+func (f *Frontend) AddDebugInternalClusterHeader(writer http.ResponseWriter, request *http.Request, hcpCluster *api.HCPOpenShiftCluster) {
+	if request.Header.Get(HeaderNameDebugInternalCluster) == "" {
+		return
+	}
+
+	logger := LoggerFromContext(request.Context())
+
+	if !f.IsAdminRequest(request) {
+		logger.Info("Ignoring " + HeaderNameDebugInternalCluster + " header: client is not an admin")
+		return
+	}
+
+	data, err := json.Marshal(api.RedactHCPOpenShiftCluster(hcpCluster))
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	writer.Header().Set(HeaderNameDebugInternalCluster, string(data))
+}
+
+// writeDatabaseError writes an error response for a failed database request.
+// If Cosmos DB throttled the request, the client is told to retry after the
+// delay Cosmos DB advises. Any other failure is an internal server error.
+func writeDatabaseError(writer http.ResponseWriter, err error) {
+	if retryAfter, ok := database.IsThrottledError(err); ok {
+		seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+		writer.Header().Set("Retry-After", strconv.Itoa(seconds))
+		arm.WriteError(writer, http.StatusTooManyRequests,
+			arm.CloudErrorCodeTooManyRequests, "",
+			"The request rate is too high. Retry after %d seconds.", seconds)
+		return
+	}
+	arm.WriteInternalServerError(writer)
+}
@@ -4,6 +4,7 @@ package frontend
 // Licensed under the Apache License 2.0.
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -13,17 +14,38 @@ import (
 
 const megabyte int64 = (1 << 20)
 
-func MiddlewareBody(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+// NewMiddlewareBody returns a middleware function that reads the body of
+// PATCH, POST and PUT requests into the request context. Bodies larger than
+// maxBodySize bytes are rejected with 413 Payload Too Large before they are
+// read in full. A non-positive maxBodySize selects DefaultMaxRequestBodySize.
+func NewMiddlewareBody(maxBodySize int64) MiddlewareFunc {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxRequestBodySize
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		middlewareBody(w, r, next, maxBodySize)
+	}
+}
+
+func middlewareBody(w http.ResponseWriter, r *http.Request, next http.HandlerFunc, maxBodySize int64) {
 	switch r.Method {
 	case http.MethodPatch, http.MethodPost, http.MethodPut:
-		// Max request body size accepted by ARM is 4 MB (assuming units in powers of 2).
-		// See https://github.com/Azure/azure-resource-manager-rpc/blob/master/v1.0/common-api-details.md#max-request-body-size
-		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4*megabyte))
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
 		if err != nil {
-			arm.WriteError(
-				w, http.StatusBadRequest,
-				arm.CloudErrorCodeInvalidResource, "",
-				"The resource definition is invalid.")
+			var maxBytesError *http.MaxBytesError
+			if errors.As(err, &maxBytesError) {
+				arm.WriteError(
+					w, http.StatusRequestEntityTooLarge,
+					arm.CloudErrorCodePayloadTooLarge, "",
+					"The request body exceeds the maximum size of %d bytes.",
+					maxBytesError.Limit)
+			} else {
+				arm.WriteError(
+					w, http.StatusBadRequest,
+					arm.CloudErrorCodeInvalidResource, "",
+					"The resource definition is invalid.")
+			}
 			return
 		}
 
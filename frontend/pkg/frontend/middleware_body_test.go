@@ -15,11 +15,12 @@ import (
 
 func TestMiddlewareBody(t *testing.T) {
 	tests := []struct {
-		name    string
-		methods []string
-		header  http.Header
-		body    []byte
-		wantErr string
+		name        string
+		methods     []string
+		maxBodySize int64
+		header      http.Header
+		body        []byte
+		wantErr     string
 	}{
 		{
 			name:    "GET request - valid",
@@ -29,7 +30,26 @@ func TestMiddlewareBody(t *testing.T) {
 			name:    "large body",
 			methods: []string{http.MethodPatch, http.MethodPost, http.MethodPut},
 			body:    bytes.Repeat([]byte{0}, int(5*megabyte)),
-			wantErr: "400: InvalidResource: The resource definition is invalid.",
+			wantErr: "413: PayloadTooLarge: The request body exceeds the maximum size of 4194304 bytes.",
+		},
+		{
+			name:        "body over configured limit",
+			methods:     []string{http.MethodPatch, http.MethodPost, http.MethodPut},
+			maxBodySize: 16,
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body:    []byte(`{"location": "eastus"}`),
+			wantErr: "413: PayloadTooLarge: The request body exceeds the maximum size of 16 bytes.",
+		},
+		{
+			name:        "body at configured limit",
+			methods:     []string{http.MethodPatch, http.MethodPost, http.MethodPut},
+			maxBodySize: 16,
+			header: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body: []byte(`{"name": "test"}`),
 		},
 		{
 			name:    "invalid media type",
@@ -90,7 +110,7 @@ func TestMiddlewareBody(t *testing.T) {
 					w.WriteHeader(http.StatusOK)
 				}
 
-				MiddlewareBody(writer, request, next)
+				NewMiddlewareBody(tt.maxBodySize)(writer, request, next)
 
 				if tt.wantErr == "" {
 					if writer.Code != http.StatusOK {
@@ -80,6 +80,7 @@ func MiddlewareLoggingPostMux(w http.ResponseWriter, r *http.Request, next http.
 	ctx = ContextWithCorrelationData(ctx, correlationData)
 
 	setHeaders(w, r, correlationData)
+	setSpanAttributes(r)
 
 	attrs := getLogAttrs(correlationData, r)
 	logger = slog.New(logger.Handler().WithAttrs(attrs))
@@ -0,0 +1,114 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+)
+
+// subscriptionLimitRetryAfter is the "Retry-After" value, in seconds, sent
+// with requests rejected for exceeding the subscription's request limit.
+const subscriptionLimitRetryAfter = 5
+
+// subscriptionRequestLimiter bounds the number of mutating requests in
+// flight for each subscription, so that one busy subscription can neither
+// starve the others nor overwhelm Cluster Service.
+//
+// The counts are kept in memory and are not shared between frontend
+// replicas, so a subscription can have up to the limit times the number
+// of replicas requests in flight overall.
+type subscriptionRequestLimiter struct {
+	limit int
+
+	mutex    sync.Mutex
+	inFlight map[string]int
+}
+
+// newSubscriptionRequestLimiter returns a subscriptionRequestLimiter for
+// the given per-subscription limit, or nil if the limit is not positive.
+func newSubscriptionRequestLimiter(limit int) *subscriptionRequestLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &subscriptionRequestLimiter{
+		limit:    limit,
+		inFlight: make(map[string]int),
+	}
+}
+
+// Acquire counts a new request for the subscription and returns true, or
+// returns false if the subscription is already at its limit. Every
+// successful Acquire must be followed by a Release.
+func (l *subscriptionRequestLimiter) Acquire(subscriptionID string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Subscription IDs are case-insensitive.
+	key := strings.ToLower(subscriptionID)
+
+	if l.inFlight[key] >= l.limit {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+// Release uncounts a request for the subscription.
+func (l *subscriptionRequestLimiter) Release(subscriptionID string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	key := strings.ToLower(subscriptionID)
+
+	// Drop idle subscriptions so the map does not grow without bound.
+	if l.inFlight[key] <= 1 {
+		delete(l.inFlight, key)
+	} else {
+		l.inFlight[key]--
+	}
+}
+
+// NewMiddlewareSubscriptionLimit returns a middleware function that rejects
+// mutating requests with 429 Too Many Requests while the subscription has
+// maxRequests mutating requests in flight. Read-only requests are exempt.
+// A non-positive maxRequests disables the limit.
+func NewMiddlewareSubscriptionLimit(maxRequests int) MiddlewareFunc {
+	limiter := newSubscriptionRequestLimiter(maxRequests)
+
+	return func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		if limiter == nil {
+			next(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			// These methods are read-only and are not limited.
+			next(w, r)
+			return
+		}
+
+		subscriptionID := r.PathValue(PathSegmentSubscriptionID)
+		if !limiter.Acquire(subscriptionID) {
+			logger := LoggerFromContext(r.Context())
+			logger.Info(fmt.Sprintf("Subscription '%s' exceeded %d concurrent requests", subscriptionID, limiter.limit))
+			w.Header().Set("Retry-After", strconv.Itoa(subscriptionLimitRetryAfter))
+			arm.WriteError(
+				w, http.StatusTooManyRequests,
+				arm.CloudErrorCodeTooManyRequests, "",
+				"Too many concurrent requests for subscription '%s'. Retry after %d seconds.",
+				subscriptionID, subscriptionLimitRetryAfter)
+			return
+		}
+		defer limiter.Release(subscriptionID)
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,83 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestMiddlewareSubscriptionLimit(t *testing.T) {
+	const maxRequests = 2
+	const subscriptionA = "00000000-0000-0000-0000-00000000000a"
+	const subscriptionB = "00000000-0000-0000-0000-00000000000b"
+
+	middleware := NewMiddlewareSubscriptionLimit(maxRequests)
+
+	newRequest := func(method, subscriptionID string) *http.Request {
+		request := httptest.NewRequest(method, "/subscriptions/"+subscriptionID, nil)
+		request = request.WithContext(ContextWithLogger(context.Background(), testLogger))
+		request.SetPathValue(PathSegmentSubscriptionID, subscriptionID)
+		return request
+	}
+
+	serve := func(request *http.Request, next http.HandlerFunc) *httptest.ResponseRecorder {
+		writer := httptest.NewRecorder()
+		middleware(writer, request, next)
+		return writer
+	}
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// Fill subscription A with requests that block until released.
+	var entered sync.WaitGroup
+	var done sync.WaitGroup
+	release := make(chan struct{})
+	blocked := func(w http.ResponseWriter, r *http.Request) {
+		entered.Done()
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}
+
+	entered.Add(maxRequests)
+	done.Add(maxRequests)
+	for range maxRequests {
+		go func() {
+			defer done.Done()
+			serve(newRequest(http.MethodPut, subscriptionA), blocked)
+		}()
+	}
+	entered.Wait()
+
+	writer := serve(newRequest(http.MethodPut, subscriptionA), ok)
+	if writer.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status code %d for subscription A, got %d", http.StatusTooManyRequests, writer.Code)
+	}
+	if writer.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+
+	writer = serve(newRequest(http.MethodGet, subscriptionA), ok)
+	if writer.Code != http.StatusOK {
+		t.Errorf("Expected status code %d for a read from subscription A, got %d", http.StatusOK, writer.Code)
+	}
+
+	writer = serve(newRequest(http.MethodPut, subscriptionB), ok)
+	if writer.Code != http.StatusOK {
+		t.Errorf("Expected status code %d for subscription B, got %d", http.StatusOK, writer.Code)
+	}
+
+	close(release)
+	done.Wait()
+
+	writer = serve(newRequest(http.MethodPut, subscriptionA), ok)
+	if writer.Code != http.StatusOK {
+		t.Errorf("Expected status code %d for subscription A after release, got %d", http.StatusOK, writer.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+)
+
+// MiddlewareTracing starts a server span for the request, continuing any
+// trace context propagated in the request headers. It must follow
+// MiddlewareLogging so the response status code can be recorded.
+func MiddlewareTracing(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path)))
+	defer span.End()
+
+	next(w, r.WithContext(ctx))
+
+	if lrw, ok := w.(*LoggingResponseWriter); ok {
+		span.SetAttributes(attribute.Int("http.response.status_code", lrw.statusCode))
+		if lrw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(lrw.statusCode))
+		}
+	}
+}
+
+// setSpanAttributes names the request's server span after the matched
+// route and records the resource the request operates on. Path values
+// are only available once the request has been multiplexed.
+func setSpanAttributes(r *http.Request) {
+	span := trace.SpanFromContext(r.Context())
+	if !span.IsRecording() {
+		return
+	}
+
+	if r.Pattern != "" {
+		span.SetName(r.Pattern)
+		span.SetAttributes(attribute.String("http.route", r.Pattern))
+	}
+
+	if subscriptionID := r.PathValue(PathSegmentSubscriptionID); subscriptionID != "" {
+		span.SetAttributes(attribute.String("aro.subscription_id", subscriptionID))
+	}
+
+	if resourceGroup := r.PathValue(PathSegmentResourceGroupName); resourceGroup != "" {
+		span.SetAttributes(attribute.String("aro.resource_group", resourceGroup))
+	}
+
+	switch {
+	case r.PathValue(PathSegmentNodePoolName) != "":
+		span.SetAttributes(attribute.String("aro.resource_type", api.NodePoolResourceType.String()))
+	case r.PathValue(PathSegmentResourceName) != "":
+		span.SetAttributes(attribute.String("aro.resource_type", api.ClusterResourceType.String()))
+	}
+}
@@ -0,0 +1,164 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/api/v20240610preview/generated"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestTracingCreateNodePool(t *testing.T) {
+	ctx := context.Background()
+
+	mockCSClient := ocm.NewMockClusterServiceClient()
+	f := &Frontend{
+		dbClient:             newTracingDBClient(database.NewCache()),
+		metrics:              NewPrometheusEmitter(prometheus.NewRegistry()),
+		clusterServiceClient: newTracingClusterServiceClient(&mockCSClient),
+	}
+
+	err := f.dbClient.CreateSubscriptionDoc(ctx, database.NewSubscriptionDocument(dummySubscrtiptionId, &arm.Subscription{
+		State:            arm.SubscriptionStateRegistered,
+		RegistrationDate: api.Ptr(time.Now().String()),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clusterResourceID, err := arm.ParseResourceID(dummyClusterID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestHeader := make(http.Header)
+	requestHeader.Add(arm.HeaderNameHomeTenantID, dummyTenantId)
+
+	hcpCluster := api.NewDefaultHCPOpenShiftCluster()
+	hcpCluster.Name = dummyClusterName
+	csCluster, err := f.BuildCSCluster(clusterResourceID, requestHeader, hcpCluster, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.clusterServiceClient.PostCSCluster(ctx, csCluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clusterDoc := database.NewResourceDocument(clusterResourceID)
+	clusterDoc.InternalID, err = ocm.NewInternalID(dummyClusterHREF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.dbClient.CreateResourceDoc(ctx, clusterDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only record spans for the request itself.
+	recorder := tracetest.NewSpanRecorder()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousTracerProvider) })
+
+	body, err := json.Marshal(generated.HcpOpenShiftClusterNodePoolResource{
+		Location: &dummyLocation,
+		Properties: &generated.NodePoolProperties{
+			Spec: &generated.NodePoolSpec{
+				Platform: &generated.NodePoolPlatformProfile{VMSize: &dummyVMSize},
+				Version:  &generated.VersionProfile{ID: &dummyVersionID, ChannelGroup: &dummyChannelGroup},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(f.routes())
+	ts.Config.BaseContext = func(net.Listener) context.Context {
+		ctx := context.Background()
+		ctx = ContextWithLogger(ctx, testLogger)
+		ctx = ContextWithDBClient(ctx, f.dbClient)
+		ctx = ContextWithSystemData(ctx, &arm.SystemData{})
+		return ctx
+	}
+	defer ts.Close()
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+dummyNodePoolID+"?api-version=2024-06-10-preview", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.Body.Close()
+
+	if rs.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, got %d", http.StatusCreated, rs.StatusCode)
+	}
+
+	spans := recorder.Ended()
+
+	var serverSpan sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if strings.HasPrefix(span.Name(), http.MethodPut+" ") {
+			serverSpan = span
+		}
+	}
+	if serverSpan == nil {
+		t.Fatal("expected a server span for the request")
+	}
+
+	attributes := make(map[string]string)
+	for _, attr := range serverSpan.Attributes() {
+		attributes[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attributes["aro.subscription_id"] != dummySubscrtiptionId {
+		t.Errorf("expected subscription ID attribute %q, got %q", dummySubscrtiptionId, attributes["aro.subscription_id"])
+	}
+	if attributes["aro.resource_type"] != api.NodePoolResourceType.String() {
+		t.Errorf("expected resource type attribute %q, got %q", api.NodePoolResourceType, attributes["aro.resource_type"])
+	}
+
+	for _, name := range []string{
+		"database.GetResourceDoc",
+		"database.CreateOperationDoc",
+		"database.CreateResourceDoc",
+		"clusterservice.PostCSNodePool",
+	} {
+		var found bool
+		for _, span := range spans {
+			if span.Name() != name {
+				continue
+			}
+			found = true
+			if span.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+				t.Errorf("expected span %s to be a child of the server span", name)
+			}
+		}
+		if !found {
+			t.Errorf("expected a %s span", name)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package frontend
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/Azure/ARO-HCP/internal/api"
 	"github.com/Azure/ARO-HCP/internal/api/arm"
@@ -25,9 +26,11 @@ func MiddlewareValidateAPIVersion(w http.ResponseWriter, r *http.Request, next h
 		arm.WriteError(
 			w, http.StatusBadRequest,
 			arm.CloudErrorCodeInvalidResourceType, "",
-			"The resource type '%s' could not be found API version '%s'.",
+			"The resource type '%s' could not be found for API version '%s'. "+
+				"The supported api-versions are '%s'.",
 			api.ClusterResourceType,
-			apiVersion)
+			apiVersion,
+			strings.Join(api.ListVersions(), ", "))
 	} else {
 		logger = logger.With("api_version", apiVersion)
 		ctx = ContextWithLogger(ctx, logger)
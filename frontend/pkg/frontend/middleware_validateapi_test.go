@@ -0,0 +1,85 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+)
+
+func TestMiddlewareValidateAPIVersion(t *testing.T) {
+	tests := []struct {
+		name           string
+		apiVersion     string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "supported version",
+			apiVersion:     "2024-06-10-preview",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing version",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   arm.CloudErrorCodeInvalidParameter,
+		},
+		{
+			name:           "bogus version",
+			apiVersion:     "1999-01-01",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   arm.CloudErrorCodeInvalidResourceType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var nextCalled bool
+			next := func(w http.ResponseWriter, r *http.Request) {
+				nextCalled = true
+				if _, err := VersionFromContext(r.Context()); err != nil {
+					t.Error(err)
+				}
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/subscriptions?api-version="+tt.apiVersion, nil)
+			request = request.WithContext(ContextWithLogger(context.Background(), testLogger))
+			writer := httptest.NewRecorder()
+
+			MiddlewareValidateAPIVersion(writer, request, next)
+
+			if writer.Code != tt.expectedStatus {
+				t.Errorf("Expected status code %d, got %d", tt.expectedStatus, writer.Code)
+			}
+			if nextCalled != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Unexpected call to next handler: %t", nextCalled)
+			}
+			if tt.expectedCode == "" {
+				return
+			}
+
+			var cloudError arm.CloudError
+			if err := json.Unmarshal(writer.Body.Bytes(), &cloudError); err != nil {
+				t.Fatal(err)
+			}
+			if cloudError.Code != tt.expectedCode {
+				t.Errorf("Expected error code %s, got %s", tt.expectedCode, cloudError.Code)
+			}
+			if tt.apiVersion != "" {
+				for _, version := range api.ListVersions() {
+					if !strings.Contains(cloudError.Message, version) {
+						t.Errorf("Expected error message to list supported version '%s': %s", version, cloudError.Message)
+					}
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package frontend
 // Licensed under the Apache License 2.0.
 
 import (
+	"encoding/json"
 	"net/http"
 	"regexp"
 	"strings"
@@ -15,8 +16,11 @@ import (
 )
 
 // Referenced in https://learn.microsoft.com/en-us/azure/azure-resource-manager/management/resource-name-rules#microsoftresources
-var rxHCPOpenShiftClusterResourceName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{2,53}$`)
-var rxNodePoolResourceName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{2,14}$`)
+//
+// Cluster Service also uses these names as DNS labels, so they must not end
+// with a hyphen.
+var rxHCPOpenShiftClusterResourceName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{1,52}[a-zA-Z0-9]$`)
+var rxNodePoolResourceName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{1,13}[a-zA-Z0-9]$`)
 
 func MiddlewareValidateStatic(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	// To conform with "OAPI012: Resource IDs must not be case sensitive"
@@ -62,7 +66,42 @@ func MiddlewareValidateStatic(w http.ResponseWriter, r *http.Request, next http.
 				return
 			}
 		}
+
+		// Like the resource name, the resource type is determined by the
+		// request URL. Reject a request body with a conflicting resource
+		// type rather than silently disregarding it.
+		switch r.Method {
+		case http.MethodPatch, http.MethodPut:
+			if bodyType := resourceTypeFromBody(r); bodyType != "" && !strings.EqualFold(bodyType, resource.ResourceType.String()) {
+				arm.WriteError(w, http.StatusBadRequest,
+					arm.CloudErrorCodeInvalidResourceType,
+					resource.String(),
+					"The resource type '%s' in the request body does not match the resource type '%s' in the request URL.",
+					bodyType, resource.ResourceType)
+				return
+			}
+		}
 	}
 
 	next(w, r)
 }
+
+// resourceTypeFromBody returns the "type" field of the request body, or an
+// empty string if there is no request body or the field is absent. Request
+// bodies that fail to parse are left for the request handler to reject.
+func resourceTypeFromBody(r *http.Request) string {
+	var resource struct {
+		Type string `json:"type"`
+	}
+
+	body, err := BodyFromContext(r.Context())
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+
+	if json.Unmarshal(body, &resource) != nil {
+		return ""
+	}
+
+	return resource.Type
+}
@@ -22,8 +22,10 @@ func TestMiddlewareValidateStatic(t *testing.T) {
 	})
 
 	tests := []struct {
-		name string
-		path string
+		name   string
+		method string
+		path   string
+		body   string
 
 		operationsId       string
 		expectedStatusCode int
@@ -69,6 +71,23 @@ func TestMiddlewareValidateStatic(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedBody:       "The Resource 'MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/a' under resource group 'MyResourceGroup' does not conform to the naming restriction.",
 		},
+		{
+			name:               "Invalid hcpopenshiftcluster resource name, ends with a '-'",
+			path:               "/SUBSCRIPTIONS/00000000-0000-0000-0000-000000000000/RESOURCEGROUPS/MyResourceGroup/PROVIDERS/MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/garbage-",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "The Resource 'MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/garbage-' under resource group 'MyResourceGroup' does not conform to the naming restriction.",
+		},
+		{
+			name:               "Valid hcpopenshiftcluster resource name, maximum length",
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/" + "c" + strings.Repeat("0", 53),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Invalid hcpopenshiftcluster resource name, one character too long",
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/" + "c" + strings.Repeat("0", 54),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "does not conform to the naming restriction.",
+		},
 		{
 			name:               "Invalid node pool resource name",
 			path:               "/SUBSCRIPTIONS/00000000-0000-0000-0000-000000000000/RESOURCEGROUPS/MyResourceGroup/PROVIDERS/MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/myCluster/NODEPOOLS/$",
@@ -81,6 +100,17 @@ func TestMiddlewareValidateStatic(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedBody:       "The Resource 'MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/NODEPOOLS/-abcde' under resource group 'MyResourceGroup' does not conform to the naming restriction.",
 		},
+		{
+			name:               "Invalid node pool resource name, ends with a '-'",
+			path:               "/SUBSCRIPTIONS/00000000-0000-0000-0000-000000000000/RESOURCEGROUPS/MyResourceGroup/PROVIDERS/MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/myCluster/NODEPOOLS/abcde-",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "The Resource 'MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/NODEPOOLS/abcde-' under resource group 'MyResourceGroup' does not conform to the naming restriction.",
+		},
+		{
+			name:               "Valid node pool resource name, maximum length",
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/MyCluster/NodePools/np-012345678901",
+			expectedStatusCode: http.StatusOK,
+		},
 		{
 			name:               "Invalid node pool resource name, too long",
 			path:               "/SUBSCRIPTIONS/00000000-0000-0000-0000-000000000000/RESOURCEGROUPS/MyResourceGroup/PROVIDERS/MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/myCluster/NODEPOOLS/07B4gc00vjA2C8KL3Ns4No9fi",
@@ -93,6 +123,36 @@ func TestMiddlewareValidateStatic(t *testing.T) {
 			expectedStatusCode: http.StatusBadRequest,
 			expectedBody:       "The Resource 'MICROSOFT.REDHATOPENSHIFT/HCPOPENSHIFTCLUSTERS/NODEPOOLS/a' under resource group 'MyResourceGroup' does not conform to the naming restriction.",
 		},
+		{
+			name:               "Matching resource type in hcpopenshiftcluster request body",
+			method:             http.MethodPut,
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/MyCluster",
+			body:               `{"type": "Microsoft.RedHatOpenShift/hcpOpenShiftClusters"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Mismatched resource type in hcpopenshiftcluster request body",
+			method:             http.MethodPut,
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/MyCluster",
+			body:               `{"type": "Microsoft.RedHatOpenShift/hcpOpenShiftClusters/nodePools"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "The resource type 'Microsoft.RedHatOpenShift/hcpOpenShiftClusters/nodePools' in the request body does not match the resource type 'Microsoft.RedHatOpenShift/HCPOpenShiftClusters' in the request URL.",
+		},
+		{
+			name:               "Mismatched resource type in node pool request body",
+			method:             http.MethodPatch,
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/MyCluster/NodePools/MyNodePool",
+			body:               `{"type": "Microsoft.RedHatOpenShift/hcpOpenShiftClusters"}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "The resource type 'Microsoft.RedHatOpenShift/hcpOpenShiftClusters' in the request body does not match the resource type 'Microsoft.RedHatOpenShift/HCPOpenShiftClusters/NodePools' in the request URL.",
+		},
+		{
+			name:               "Resource type omitted from request body",
+			method:             http.MethodPut,
+			path:               "/Subscriptions/42d9eac4-d29a-4d6e-9e26-3439758b1491/ResourceGroups/MyResourceGroup/Providers/Microsoft.RedHatOpenShift/HCPOpenShiftClusters/MyCluster",
+			body:               `{"location": "eastus"}`,
+			expectedStatusCode: http.StatusOK,
+		},
 		{
 			name:               "Resource name is a valid subscription ID",
 			path:               "/SUBSCRIPTIONS/00000000-0000-0000-0000-000000000000",
@@ -102,8 +162,16 @@ func TestMiddlewareValidateStatic(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			req := httptest.NewRequest("GET", "http://example.com"+tc.path, nil)
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "http://example.com"+tc.path, nil)
 			req = req.WithContext(ContextWithOriginalPath(req.Context(), tc.path))
+			if tc.body != "" {
+				req = req.WithContext(ContextWithBody(req.Context(), []byte(tc.body)))
+			}
 
 			// Use httptest.ResponseRecorder to record the response
 			w := httptest.NewRecorder()
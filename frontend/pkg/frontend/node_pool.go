@@ -4,6 +4,7 @@ package frontend
 // Licensed under the Apache License 2.0.
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -57,16 +58,25 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		return
 	}
 
+	body, err := BodyFromContext(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
 	doc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
 	if err != nil && !errors.Is(err, database.ErrNotFound) {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
 	var updating = (doc != nil)
 	var operationRequest database.OperationRequest
 
+	var currentCSNodePool *cmv1.NodePool
+	var currentNodePool *api.HCPOpenShiftClusterNodePool
 	var versionedCurrentNodePool api.VersionedHCPOpenShiftClusterNodePool
 	var versionedRequestNodePool api.VersionedHCPOpenShiftClusterNodePool
 	var successStatusCode int
@@ -78,14 +88,14 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		// No special treatment here for "not found" errors. A "not found"
 		// error indicates the database has gotten out of sync and so it's
 		// appropriate to fail.
-		csNodePool, err := f.clusterServiceClient.GetCSNodePool(ctx, doc.InternalID)
+		currentCSNodePool, err = f.clusterServiceClient.GetCSNodePool(ctx, doc.InternalID)
 		if err != nil {
 			logger.Error(fmt.Sprintf("failed to fetch CS node pool for %s: %v", resourceID, err))
 			arm.WriteInternalServerError(writer)
 			return
 		}
 
-		hcpNodePool := ConvertCStoNodePool(resourceID, csNodePool)
+		currentNodePool = ConvertCStoNodePool(resourceID, currentCSNodePool)
 
 		// Do not set the TrackedResource.Tags field here. We need
 		// the Tags map to remain nil so we can see if the request
@@ -96,12 +106,12 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		// This is slightly repetitive for the sake of clarify on PUT vs PATCH.
 		switch request.Method {
 		case http.MethodPut:
-			versionedCurrentNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(hcpNodePool)
+			versionedCurrentNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(currentNodePool)
 			versionedRequestNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(nil)
 			successStatusCode = http.StatusOK
 		case http.MethodPatch:
-			versionedCurrentNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(hcpNodePool)
-			versionedRequestNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(hcpNodePool)
+			versionedCurrentNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(currentNodePool)
+			versionedRequestNodePool = versionedInterface.NewHCPOpenShiftClusterNodePool(patchScalingMode(currentNodePool, body))
 			successStatusCode = http.StatusAccepted
 		}
 	} else {
@@ -122,27 +132,19 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		doc = database.NewResourceDocument(resourceID)
 	}
 
-	// CheckForProvisioningStateConflict does not log conflict errors
+	// RejectProvisioningStateConflict does not log conflict errors
 	// but does log unexpected errors like database failures.
-	cloudError := f.CheckForProvisioningStateConflict(ctx, operationRequest, doc)
-	if cloudError != nil {
-		arm.WriteCloudError(writer, cloudError)
+	if f.RejectProvisioningStateConflict(ctx, writer, operationRequest, doc) {
 		return
 	}
 
-	body, err := BodyFromContext(ctx)
-	if err != nil {
-		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
-		return
-	}
 	if err = json.Unmarshal(body, versionedRequestNodePool); err != nil {
 		logger.Error(err.Error())
 		arm.WriteInvalidRequestContentError(writer, err)
 		return
 	}
 
-	cloudError = versionedRequestNodePool.ValidateStatic(versionedCurrentNodePool, updating, request.Method)
+	cloudError := versionedRequestNodePool.ValidateStatic(versionedCurrentNodePool, updating, request.Method)
 	if cloudError != nil {
 		logger.Error(cloudError.Error())
 		arm.WriteCloudError(writer, cloudError)
@@ -153,7 +155,33 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 	versionedRequestNodePool.Normalize(hcpNodePool)
 
 	hcpNodePool.Name = request.PathValue(PathSegmentNodePoolName)
-	csNodePool, err := f.BuildCSNodePool(ctx, hcpNodePool, updating)
+
+	// The node pool version cannot be updated,
+	// so only check version skew on create.
+	var clusterDoc *database.ResourceDocument
+	if !updating {
+		clusterDoc, err = f.dbClient.GetResourceDoc(ctx, resourceID.GetParent())
+		if err != nil {
+			logger.Error(err.Error())
+			writeDatabaseError(writer, err)
+			return
+		}
+
+		cloudError = f.CheckNodePoolVersionSkew(ctx, clusterDoc, hcpNodePool)
+		if cloudError != nil {
+			logger.Error(cloudError.Error())
+			arm.WriteCloudError(writer, cloudError)
+			return
+		}
+	}
+
+	var csNodePool *cmv1.NodePool
+	if updating {
+		// Only send Cluster Service the fields the request changes.
+		csNodePool, err = f.BuildCSNodePoolUpdate(currentNodePool, hcpNodePool)
+	} else {
+		csNodePool, err = f.BuildCSNodePool(ctx, hcpNodePool, updating)
+	}
 	if err != nil {
 		logger.Error(err.Error())
 		arm.WriteInternalServerError(writer)
@@ -161,26 +189,24 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 	}
 
 	if updating {
-		logger.Info(fmt.Sprintf("updating resource %s", resourceID))
-		csNodePool, err = f.clusterServiceClient.UpdateCSNodePool(ctx, doc.InternalID, csNodePool)
-		if err != nil {
-			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
-			return
+		if csNodePool.Empty() {
+			logger.Info(fmt.Sprintf("no changes to resource %s in Cluster Service", resourceID))
+			csNodePool = currentCSNodePool
+		} else {
+			logger.Info(fmt.Sprintf("updating resource %s", resourceID))
+			csNodePool, err = f.clusterServiceClient.UpdateCSNodePool(ctx, doc.InternalID, csNodePool)
+			if err != nil {
+				logger.Error(err.Error())
+				arm.WriteCloudError(writer, CSErrorToCloudError(err, csNodePoolAttributeTargets))
+				return
+			}
 		}
 	} else {
 		logger.Info(fmt.Sprintf("creating resource %s", resourceID))
-		clusterDoc, err := f.dbClient.GetResourceDoc(ctx, resourceID.GetParent())
-		if err != nil {
-			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
-			return
-		}
-
 		csNodePool, err = f.clusterServiceClient.PostCSNodePool(ctx, clusterDoc.InternalID, csNodePool)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			arm.WriteCloudError(writer, CSErrorToCloudError(err, csNodePoolAttributeTargets))
 			return
 		}
 
@@ -192,19 +218,19 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		}
 	}
 
-	operationDoc := database.NewOperationDocument(operationRequest, doc.Key, doc.InternalID)
+	operationDoc := newOperationDocument(ctx, operationRequest, doc.Key, doc.InternalID)
 
 	err = f.dbClient.CreateOperationDoc(ctx, operationDoc)
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
 	err = f.ExposeOperation(writer, request, operationDoc.ID)
 	if err != nil {
 		logger.Error(err.Error())
-		arm.WriteInternalServerError(writer)
+		writeDatabaseError(writer, err)
 		return
 	}
 
@@ -236,7 +262,7 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		err = f.dbClient.CreateResourceDoc(ctx, doc)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 		logger.Info(fmt.Sprintf("document created for %s", resourceID))
@@ -244,7 +270,7 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		updated, err := f.dbClient.UpdateResourceDoc(ctx, resourceID, updateResourceMetadata)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 		if updated {
@@ -254,7 +280,7 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 		doc, err = f.dbClient.GetResourceDoc(ctx, resourceID)
 		if err != nil {
 			logger.Error(err.Error())
-			arm.WriteInternalServerError(writer)
+			writeDatabaseError(writer, err)
 			return
 		}
 	}
@@ -272,6 +298,43 @@ func (f *Frontend) CreateOrUpdateNodePool(writer http.ResponseWriter, request *h
 	}
 }
 
+// patchScalingMode returns a copy of an existing node pool, prepared for
+// a PATCH request body to be overlayed onto it. Replicas and autoscaling
+// are mutually exclusive, so if the request body sets one but not the
+// other, the other is cleared to let the request switch scaling modes.
+// A JSON null value counts as not set.
+func patchScalingMode(nodePool *api.HCPOpenShiftClusterNodePool, body []byte) *api.HCPOpenShiftClusterNodePool {
+	var patch struct {
+		Properties struct {
+			Spec struct {
+				Replicas    json.RawMessage `json:"replicas"`
+				AutoScaling json.RawMessage `json:"autoScaling"`
+			} `json:"spec"`
+		} `json:"properties"`
+	}
+
+	// A malformed body is reported when it gets
+	// unmarshalled into the versioned node pool.
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nodePool
+	}
+
+	isSet := func(value json.RawMessage) bool {
+		return value != nil && !bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+	}
+
+	patched := *nodePool
+	spec := patch.Properties.Spec
+	switch {
+	case isSet(spec.AutoScaling) && !isSet(spec.Replicas):
+		patched.Properties.Spec.Replicas = 0
+	case isSet(spec.Replicas) && !isSet(spec.AutoScaling):
+		patched.Properties.Spec.AutoScaling = nil
+	}
+
+	return &patched
+}
+
 // the necessary conversions for the API version of the request.
 func marshalCSNodePool(csNodePool *cmv1.NodePool, doc *database.ResourceDocument, versionedInterface api.Version) ([]byte, error) {
 	hcpNodePool := ConvertCStoNodePool(doc.Key, csNodePool)
@@ -6,12 +6,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/Azure/ARO-HCP/internal/api"
@@ -149,6 +153,80 @@ func TestCreateNodePool(t *testing.T) {
 	}
 }
 
+// throttledDBClient simulates Cosmos DB exhausting its request units
+// whenever a resource document is read.
+type throttledDBClient struct {
+	database.DBClient
+}
+
+func (c *throttledDBClient) GetResourceDoc(ctx context.Context, resourceID *arm.ResourceID) (*database.ResourceDocument, error) {
+	return nil, fmt.Errorf("failed to read resource document: %w", &azcore.ResponseError{
+		StatusCode: http.StatusTooManyRequests,
+		RawResponse: &http.Response{
+			Header: http.Header{"X-Ms-Retry-After-Ms": []string{"2500"}},
+		},
+	})
+}
+
+func TestCreateNodePoolThrottled(t *testing.T) {
+	requestBody := generated.HcpOpenShiftClusterNodePoolResource{
+		Location:   &dummyLocation,
+		Properties: &generated.NodePoolProperties{Spec: &generated.NodePoolSpec{Platform: &generated.NodePoolPlatformProfile{VMSize: &dummyVMSize}, Version: &generated.VersionProfile{ID: &dummyVersionID, ChannelGroup: &dummyChannelGroup}}},
+	}
+	mockCSClient := ocm.NewMockClusterServiceClient()
+
+	f := &Frontend{
+		dbClient:             &throttledDBClient{DBClient: database.NewCache()},
+		metrics:              NewPrometheusEmitter(prometheus.NewRegistry()),
+		clusterServiceClient: &mockCSClient,
+	}
+
+	err := f.dbClient.CreateSubscriptionDoc(context.TODO(), &database.SubscriptionDocument{
+		BaseDocument: database.BaseDocument{
+			ID: dummySubscrtiptionId,
+		},
+		Subscription: &arm.Subscription{
+			State:            arm.SubscriptionStateRegistered,
+			RegistrationDate: api.Ptr(time.Now().String()),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(requestBody)
+
+	ts := httptest.NewServer(f.routes())
+	defer ts.Close()
+	ts.Config.BaseContext = func(net.Listener) context.Context {
+		ctx := context.Background()
+		ctx = ContextWithLogger(ctx, testLogger) // defined in frontend_test.go
+		ctx = ContextWithDBClient(ctx, f.dbClient)
+		ctx = ContextWithSystemData(ctx, &arm.SystemData{})
+
+		return ctx
+	}
+
+	req, err := http.NewRequest(http.MethodPut, ts.URL+dummyNodePoolID+"?api-version=2024-06-10-preview", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rs, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Body.Close()
+
+	if rs.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected status code %d, got %d", http.StatusTooManyRequests, rs.StatusCode)
+	}
+	if retryAfter := rs.Header.Get("Retry-After"); retryAfter != "3" {
+		t.Errorf("expected Retry-After '3', got '%s'", retryAfter)
+	}
+}
+
 // TODO: Fix the update logic for this test.
 
 // func TestUpdateNodePool(t *testing.T) {
@@ -279,3 +357,276 @@ func TestCreateNodePool(t *testing.T) {
 // 		})
 // 	}
 // }
+
+func TestPatchScalingMode(t *testing.T) {
+	tests := []struct {
+		name                string
+		replicas            int32
+		autoScaling         *api.NodePoolAutoScaling
+		body                string
+		expectedReplicas    int32
+		expectedAutoScaling *api.NodePoolAutoScaling
+	}{
+		{
+			name:                "Switch to autoscaling",
+			replicas:            3,
+			body:                `{"properties":{"spec":{"autoScaling":{"min":1,"max":5}}}}`,
+			expectedReplicas:    0,
+			expectedAutoScaling: nil,
+		},
+		{
+			name:                "Switch to replicas",
+			autoScaling:         &api.NodePoolAutoScaling{Min: 1, Max: 5},
+			body:                `{"properties":{"spec":{"replicas":3}}}`,
+			expectedReplicas:    0,
+			expectedAutoScaling: nil,
+		},
+		{
+			name:                "Null autoscaling is not set",
+			replicas:            3,
+			body:                `{"properties":{"spec":{"autoScaling":null}}}`,
+			expectedReplicas:    3,
+			expectedAutoScaling: nil,
+		},
+		{
+			name:                "Replicas with null autoscaling",
+			autoScaling:         &api.NodePoolAutoScaling{Min: 1, Max: 5},
+			body:                `{"properties":{"spec":{"replicas":3,"autoScaling":null}}}`,
+			expectedReplicas:    0,
+			expectedAutoScaling: nil,
+		},
+		{
+			name:                "Scaling not patched",
+			autoScaling:         &api.NodePoolAutoScaling{Min: 1, Max: 5},
+			body:                `{"tags":{"a":"b"}}`,
+			expectedAutoScaling: &api.NodePoolAutoScaling{Min: 1, Max: 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			nodePool := api.NewDefaultHCPOpenShiftClusterNodePool()
+			nodePool.Properties.Spec.Replicas = test.replicas
+			nodePool.Properties.Spec.AutoScaling = test.autoScaling
+
+			patched := patchScalingMode(nodePool, []byte(test.body))
+
+			if patched.Properties.Spec.Replicas != test.expectedReplicas {
+				t.Errorf("expected replicas %d, got %d", test.expectedReplicas, patched.Properties.Spec.Replicas)
+			}
+			if !reflect.DeepEqual(patched.Properties.Spec.AutoScaling, test.expectedAutoScaling) {
+				t.Errorf("expected autoScaling %v, got %v", test.expectedAutoScaling, patched.Properties.Spec.AutoScaling)
+			}
+
+			// The existing node pool must not be modified.
+			if nodePool.Properties.Spec.Replicas != test.replicas || nodePool.Properties.Spec.AutoScaling != test.autoScaling {
+				t.Error("existing node pool was modified")
+			}
+		})
+	}
+}
+
+func TestBuildCSNodePoolUpdate(t *testing.T) {
+	current := api.NewDefaultHCPOpenShiftClusterNodePool()
+	current.Properties.Spec.Platform.VMSize = dummyVMSize
+	current.Properties.Spec.Replicas = 3
+	current.Properties.Spec.Labels = map[string]string{"a": "b"}
+
+	f := &Frontend{}
+
+	t.Run("No changes", func(t *testing.T) {
+		updated := *current
+
+		csNodePool, err := f.BuildCSNodePoolUpdate(current, &updated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !csNodePool.Empty() {
+			t.Errorf("expected an empty node pool, got %v", csNodePool)
+		}
+	})
+
+	t.Run("Switch to autoscaling", func(t *testing.T) {
+		updated := *current
+		updated.Properties.Spec.Replicas = 0
+		updated.Properties.Spec.AutoScaling = &api.NodePoolAutoScaling{Min: 2, Max: 6}
+
+		csNodePool, err := f.BuildCSNodePoolUpdate(current, &updated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := csNodePool.GetReplicas(); ok {
+			t.Error("expected replicas to be omitted")
+		}
+		if _, ok := csNodePool.GetLabels(); ok {
+			t.Error("expected unchanged labels to be omitted")
+		}
+		autoscaling, ok := csNodePool.GetAutoscaling()
+		if !ok {
+			t.Fatal("expected autoscaling to be set")
+		}
+		if autoscaling.MinReplica() != 2 || autoscaling.MaxReplica() != 6 {
+			t.Errorf("expected autoscaling 2-6, got %d-%d", autoscaling.MinReplica(), autoscaling.MaxReplica())
+		}
+	})
+}
+
+func TestPatchNodePool(t *testing.T) {
+	const otherSubnetID = "/subscriptions/" + dummySubscrtiptionId + "/resourceGroups/" + dummyResourceGroupId +
+		"/providers/Microsoft.Network/virtualNetworks/vnet/subnets/other"
+
+	tests := []struct {
+		name                string
+		replicas            int32
+		autoScaling         *api.NodePoolAutoScaling
+		body                string
+		expectedStatusCode  int
+		expectedTarget      string
+		expectedReplicas    *int
+		expectedAutoScaling *api.NodePoolAutoScaling
+	}{
+		{
+			name:               "Instance type is immutable",
+			replicas:           3,
+			body:               `{"properties":{"spec":{"platform":{"vmSize":"Other"}}}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedTarget:     "properties.spec.platform.vmSize",
+		},
+		{
+			name:               "Subnet is immutable",
+			replicas:           3,
+			body:               `{"properties":{"spec":{"platform":{"subnetId":"` + otherSubnetID + `"}}}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedTarget:     "properties.spec.platform.subnetId",
+		},
+		{
+			name:                "Switch to autoscaling",
+			replicas:            3,
+			body:                `{"properties":{"spec":{"autoScaling":{"min":1,"max":5}}}}`,
+			expectedStatusCode:  http.StatusAccepted,
+			expectedAutoScaling: &api.NodePoolAutoScaling{Min: 1, Max: 5},
+		},
+		{
+			name:               "Switch to replicas",
+			autoScaling:        &api.NodePoolAutoScaling{Min: 1, Max: 5},
+			body:               `{"properties":{"spec":{"replicas":4}}}`,
+			expectedStatusCode: http.StatusAccepted,
+			expectedReplicas:   api.Ptr(4),
+		},
+	}
+
+	versionedInterface, ok := api.Lookup("2024-06-10-preview")
+	if !ok {
+		t.Fatal("API version 2024-06-10-preview is not registered")
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := ContextWithLogger(context.Background(), testLogger)
+
+			mockCSClient := ocm.NewMockClusterServiceClient()
+			f := &Frontend{
+				dbClient:             database.NewCache(),
+				clusterServiceClient: &mockCSClient,
+			}
+
+			nodePoolResourceID, err := arm.ParseResourceID(dummyNodePoolID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			clusterInternalID, err := ocm.NewInternalID(dummyClusterHREF)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Updates check the provisioning state of the parent cluster.
+			clusterDoc := database.NewResourceDocument(nodePoolResourceID.GetParent())
+			clusterDoc.InternalID = clusterInternalID
+			clusterDoc.ProvisioningState = arm.ProvisioningStateSucceeded
+			err = f.dbClient.CreateResourceDoc(ctx, clusterDoc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hcpNodePool := api.NewDefaultHCPOpenShiftClusterNodePool()
+			hcpNodePool.Name = dummyNodePoolName
+			hcpNodePool.Properties.Spec.Version.ID = dummyVersionID
+			hcpNodePool.Properties.Spec.Version.ChannelGroup = dummyChannelGroup
+			hcpNodePool.Properties.Spec.Platform.VMSize = dummyVMSize
+			hcpNodePool.Properties.Spec.Platform.SubnetID = "/subscriptions/" + dummySubscrtiptionId + "/resourceGroups/" + dummyResourceGroupId +
+				"/providers/Microsoft.Network/virtualNetworks/vnet/subnets/subnet"
+			hcpNodePool.Properties.Spec.Replicas = test.replicas
+			hcpNodePool.Properties.Spec.AutoScaling = test.autoScaling
+
+			csNodePool, err := f.BuildCSNodePool(ctx, hcpNodePool, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			csNodePool, err = f.clusterServiceClient.PostCSNodePool(ctx, clusterInternalID, csNodePool)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc := database.NewResourceDocument(nodePoolResourceID)
+			doc.InternalID, err = ocm.NewInternalID(csNodePool.HREF())
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc.ProvisioningState = arm.ProvisioningStateSucceeded
+			err = f.dbClient.CreateResourceDoc(ctx, doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx = ContextWithVersion(ctx, versionedInterface)
+			ctx = ContextWithResourceID(ctx, nodePoolResourceID)
+			ctx = ContextWithSystemData(ctx, nil)
+			ctx = ContextWithBody(ctx, []byte(test.body))
+
+			request := httptest.NewRequest(http.MethodPatch, dummyNodePoolID+"?api-version=2024-06-10-preview", nil)
+			request = request.WithContext(ctx)
+			request.SetPathValue(PathSegmentNodePoolName, dummyNodePoolName)
+
+			writer := httptest.NewRecorder()
+
+			f.CreateOrUpdateNodePool(writer, request)
+
+			if writer.Code != test.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatusCode, writer.Code, writer.Body.String())
+			}
+
+			if test.expectedTarget != "" {
+				if !strings.Contains(writer.Body.String(), "field is immutable") {
+					t.Errorf("expected a 'field is immutable' error, got %s", writer.Body.String())
+				}
+				if !strings.Contains(writer.Body.String(), test.expectedTarget) {
+					t.Errorf("expected an error targeting %s, got %s", test.expectedTarget, writer.Body.String())
+				}
+				return
+			}
+
+			// The mock client stores the node pool as sent, so only
+			// the changed scaling mode is expected to be present.
+			sent, err := f.clusterServiceClient.GetCSNodePool(ctx, doc.InternalID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			replicas, ok := sent.GetReplicas()
+			if test.expectedReplicas == nil {
+				if ok {
+					t.Errorf("expected replicas to be omitted, got %d", replicas)
+				}
+			} else if !ok || replicas != *test.expectedReplicas {
+				t.Errorf("expected replicas %d, got %d", *test.expectedReplicas, replicas)
+			}
+			autoscaling, ok := sent.GetAutoscaling()
+			if test.expectedAutoScaling == nil {
+				if ok {
+					t.Errorf("expected autoscaling to be omitted, got %d-%d", autoscaling.MinReplica(), autoscaling.MaxReplica())
+				}
+			} else if !ok || autoscaling.MinReplica() != int(test.expectedAutoScaling.Min) || autoscaling.MaxReplica() != int(test.expectedAutoScaling.Max) {
+				t.Errorf("expected autoscaling %d-%d, got %v", test.expectedAutoScaling.Min, test.expectedAutoScaling.Max, autoscaling)
+			}
+		})
+	}
+}
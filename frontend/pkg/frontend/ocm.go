@@ -3,7 +3,9 @@ package frontend
 import (
 	"context"
 	"fmt"
+	"maps"
 	"net/http"
+	"slices"
 
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
 	configv1 "github.com/openshift/api/config/v1"
@@ -388,3 +390,47 @@ func (f *Frontend) BuildCSNodePool(ctx context.Context, nodePool *api.HCPOpenShi
 
 	return npBuilder.Build()
 }
+
+// BuildCSNodePoolUpdate creates a CS Node Pool object containing only the
+// mutable attributes of an HCPOpenShiftClusterNodePool that differ from the
+// current node pool, so an update does not resend unchanged attributes.
+func (f *Frontend) BuildCSNodePoolUpdate(current, updated *api.HCPOpenShiftClusterNodePool) (*cmv1.NodePool, error) {
+	npBuilder := cmv1.NewNodePool()
+
+	currentSpec := &current.Properties.Spec
+	updatedSpec := &updated.Properties.Spec
+
+	if !maps.Equal(currentSpec.Labels, updatedSpec.Labels) {
+		npBuilder.Labels(updatedSpec.Labels)
+	}
+
+	if !slices.Equal(currentSpec.TuningConfigs, updatedSpec.TuningConfigs) {
+		npBuilder.TuningConfigs(updatedSpec.TuningConfigs...)
+	}
+
+	if updatedSpec.AutoScaling != nil {
+		if currentSpec.AutoScaling == nil || *currentSpec.AutoScaling != *updatedSpec.AutoScaling {
+			npBuilder.Autoscaling(cmv1.NewNodePoolAutoscaling().
+				MinReplica(int(updatedSpec.AutoScaling.Min)).
+				MaxReplica(int(updatedSpec.AutoScaling.Max)))
+		}
+	} else if currentSpec.AutoScaling != nil || currentSpec.Replicas != updatedSpec.Replicas {
+		npBuilder.Replicas(int(updatedSpec.Replicas))
+	}
+
+	taintsEqual := slices.EqualFunc(currentSpec.Taints, updatedSpec.Taints, func(a, b *api.Taint) bool {
+		return *a == *b
+	})
+	if !taintsEqual {
+		taints := make([]*cmv1.TaintBuilder, 0, len(updatedSpec.Taints))
+		for _, t := range updatedSpec.Taints {
+			taints = append(taints, cmv1.NewTaint().
+				Effect(string(t.Effect)).
+				Key(t.Key).
+				Value(t.Value))
+		}
+		npBuilder.Taints(taints...)
+	}
+
+	return npBuilder.Build()
+}
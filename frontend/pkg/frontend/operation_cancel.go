@@ -0,0 +1,130 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+// OperationActionCancel is the trailing path segment of the endpoint
+// that cancels an asynchronous operation.
+const OperationActionCancel = "cancel"
+
+// OperationCancel cancels an asynchronous create or update operation that
+// has not yet reached a terminal state. The operation status becomes
+// "Canceled" and the resource is released from the operation so that it
+// accepts new requests. The resource itself is left in place.
+//
+// Aborting the Cluster Service work is best-effort: Cluster Service offers
+// no way to interrupt work it has already started, so that work runs to
+// completion. The backend stops tracking the operation once it is canceled.
+// Delete operations cannot be canceled.
+func (f *Frontend) OperationCancel(writer http.ResponseWriter, request *http.Request) {
+	ctx := request.Context()
+	logger := LoggerFromContext(ctx)
+
+	originalPath, err := OriginalPathFromContext(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	// The request path is the operation status resource
+	// ID followed by the cancel action segment.
+	operationID, err := arm.ParseResourceID(path.Dir(originalPath))
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	doc, err := f.dbClient.GetOperationDoc(ctx, operationID.Name)
+	if err != nil {
+		logger.Error(err.Error())
+		if errors.Is(err, database.ErrNotFound) {
+			writer.WriteHeader(http.StatusNotFound)
+		} else {
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Validate the identity canceling the operation is the
+	// same identity that triggered the operation. Return 404 if not.
+	if !f.OperationIsVisible(request, doc) {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if doc.Status.IsTerminal() {
+		arm.WriteError(writer, http.StatusConflict,
+			arm.CloudErrorCodeConflict, "",
+			"Operation '%s' has already completed with status '%s'",
+			doc.ID, doc.Status)
+		return
+	}
+
+	if doc.Request == database.OperationRequestDelete {
+		arm.WriteError(writer, http.StatusConflict,
+			arm.CloudErrorCodeConflict, "",
+			"Operation '%s' deletes a resource and cannot be canceled",
+			doc.ID)
+		return
+	}
+
+	// The subscription lock keeps the backend from updating the operation
+	// while it is being canceled, so the checks below cannot go stale.
+	resourceDoc, err := f.dbClient.GetResourceDoc(ctx, doc.ExternalID)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+	if resourceDoc == nil || resourceDoc.ActiveOperationID != doc.ID {
+		arm.WriteError(writer, http.StatusConflict,
+			arm.CloudErrorCodeConflict, "",
+			"Operation '%s' completed before it could be canceled",
+			doc.ID)
+		return
+	}
+
+	err = f.CancelActiveOperation(ctx, resourceDoc)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	_, err = f.dbClient.UpdateResourceDoc(ctx, doc.ExternalID, func(updateDoc *database.ResourceDocument) bool {
+		if updateDoc.ActiveOperationID != doc.ID {
+			return false
+		}
+		updateDoc.ActiveOperationID = ""
+		updateDoc.ProvisioningState = arm.ProvisioningStateCanceled
+		return true
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	doc, err = f.dbClient.GetOperationDoc(ctx, doc.ID)
+	if err != nil {
+		logger.Error(err.Error())
+		arm.WriteInternalServerError(writer)
+		return
+	}
+
+	_, err = arm.WriteJSONResponse(writer, http.StatusOK, doc.ToStatus())
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
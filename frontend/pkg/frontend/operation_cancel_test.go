@@ -0,0 +1,208 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestOperationCancel(t *testing.T) {
+	const (
+		subscriptionID = "00000000-0000-0000-0000-000000000000"
+		tenantID       = "00000000-0000-0000-0000-00000000000a"
+		ownerClientID  = "00000000-0000-0000-0000-00000000000b"
+		otherClientID  = "00000000-0000-0000-0000-00000000000c"
+	)
+
+	resourceID, err := arm.ParseResourceID("/subscriptions/" + subscriptionID + "/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/testCluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                      string
+		docExists                 bool
+		request                   database.OperationRequest
+		status                    arm.ProvisioningState
+		clientID                  string
+		expectedStatusCode        int
+		expectedOperationStatus   arm.ProvisioningState
+		expectedProvisioningState arm.ProvisioningState
+		expectActiveOperation     bool
+	}{
+		{
+			name:                      "Running create operation is canceled",
+			docExists:                 true,
+			request:                   database.OperationRequestCreate,
+			status:                    arm.ProvisioningStateProvisioning,
+			clientID:                  ownerClientID,
+			expectedStatusCode:        http.StatusOK,
+			expectedOperationStatus:   arm.ProvisioningStateCanceled,
+			expectedProvisioningState: arm.ProvisioningStateCanceled,
+			expectActiveOperation:     false,
+		},
+		{
+			name:                      "Running update operation is canceled",
+			docExists:                 true,
+			request:                   database.OperationRequestUpdate,
+			status:                    arm.ProvisioningStateUpdating,
+			clientID:                  ownerClientID,
+			expectedStatusCode:        http.StatusOK,
+			expectedOperationStatus:   arm.ProvisioningStateCanceled,
+			expectedProvisioningState: arm.ProvisioningStateCanceled,
+			expectActiveOperation:     false,
+		},
+		{
+			name:                      "Terminal operation is rejected",
+			docExists:                 true,
+			request:                   database.OperationRequestUpdate,
+			status:                    arm.ProvisioningStateSucceeded,
+			clientID:                  ownerClientID,
+			expectedStatusCode:        http.StatusConflict,
+			expectedOperationStatus:   arm.ProvisioningStateSucceeded,
+			expectedProvisioningState: arm.ProvisioningStateSucceeded,
+			expectActiveOperation:     true,
+		},
+		{
+			name:                      "Delete operation is rejected",
+			docExists:                 true,
+			request:                   database.OperationRequestDelete,
+			status:                    arm.ProvisioningStateDeleting,
+			clientID:                  ownerClientID,
+			expectedStatusCode:        http.StatusConflict,
+			expectedOperationStatus:   arm.ProvisioningStateDeleting,
+			expectedProvisioningState: arm.ProvisioningStateDeleting,
+			expectActiveOperation:     true,
+		},
+		{
+			name:                      "Operation of another client is not visible",
+			docExists:                 true,
+			request:                   database.OperationRequestCreate,
+			status:                    arm.ProvisioningStateProvisioning,
+			clientID:                  otherClientID,
+			expectedStatusCode:        http.StatusNotFound,
+			expectedOperationStatus:   arm.ProvisioningStateProvisioning,
+			expectedProvisioningState: arm.ProvisioningStateProvisioning,
+			expectActiveOperation:     true,
+		},
+		{
+			name:               "Missing operation",
+			docExists:          false,
+			clientID:           ownerClientID,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			mockCSClient := ocm.NewMockClusterServiceClient()
+			f := &Frontend{
+				dbClient:             database.NewCache(),
+				clusterServiceClient: &mockCSClient,
+				location:             "eastus",
+			}
+
+			csCluster, err := cmv1.NewCluster().Name(resourceID.Name).Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			csCluster, err = f.clusterServiceClient.PostCSCluster(ctx, csCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			internalID, err := ocm.NewInternalID(csCluster.HREF())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			operationDoc := database.NewOperationDocument(tt.request, resourceID, internalID)
+			operationDoc.Status = tt.status
+			operationDoc.TenantID = tenantID
+			operationDoc.ClientID = ownerClientID
+			operationDoc.OperationID, err = arm.ParseResourceID(path.Join("/",
+				"subscriptions", subscriptionID,
+				"providers", api.ProviderNamespace,
+				"locations", f.location,
+				api.OperationStatusResourceTypeName, operationDoc.ID))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.docExists {
+				err = f.dbClient.CreateOperationDoc(ctx, operationDoc)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				resourceDoc := database.NewResourceDocument(resourceID)
+				resourceDoc.InternalID = internalID
+				resourceDoc.ActiveOperationID = operationDoc.ID
+				resourceDoc.ProvisioningState = tt.status
+				err = f.dbClient.CreateResourceDoc(ctx, resourceDoc)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			originalPath := operationDoc.OperationID.String() + "/" + OperationActionCancel
+			request := httptest.NewRequest(http.MethodPost, originalPath, nil)
+			ctx = ContextWithLogger(ctx, testLogger)
+			ctx = ContextWithOriginalPath(ctx, originalPath)
+			request = request.WithContext(ctx)
+			request.SetPathValue(PathSegmentSubscriptionID, subscriptionID)
+			request.Header.Set(arm.HeaderNameHomeTenantID, tenantID)
+			request.Header.Set(arm.HeaderNameClientObjectID, tt.clientID)
+
+			writer := httptest.NewRecorder()
+
+			f.OperationCancel(writer, request)
+
+			if writer.Code != tt.expectedStatusCode {
+				t.Fatalf("expected status code %d, got %d", tt.expectedStatusCode, writer.Code)
+			}
+
+			if !tt.docExists {
+				return
+			}
+
+			operationDoc, err = f.dbClient.GetOperationDoc(ctx, operationDoc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if operationDoc.Status != tt.expectedOperationStatus {
+				t.Errorf("expected operation status %s, got %s", tt.expectedOperationStatus, operationDoc.Status)
+			}
+
+			resourceDoc, err := f.dbClient.GetResourceDoc(ctx, resourceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resourceDoc.ProvisioningState != tt.expectedProvisioningState {
+				t.Errorf("expected provisioning state %s, got %s", tt.expectedProvisioningState, resourceDoc.ProvisioningState)
+			}
+			if (resourceDoc.ActiveOperationID != "") != tt.expectActiveOperation {
+				t.Errorf("unexpected active operation ID '%s'", resourceDoc.ActiveOperationID)
+			}
+
+			// Canceling an operation must never delete the resource.
+			_, err = f.clusterServiceClient.GetCSCluster(ctx, internalID)
+			if err != nil {
+				t.Errorf("unexpected Cluster Service cluster lookup error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// operationPollSweepThreshold is the number of tracked operations
+// above which expired entries are swept from the poll history.
+const operationPollSweepThreshold = 1000
+
+// operationPollLimiter tracks when each asynchronous operation was last
+// polled to enforce a minimum interval between status polls.
+//
+// The poll history is kept in memory and is not shared between frontend
+// replicas. Polls that a load balancer spreads across N replicas can
+// therefore arrive up to N times as often as the interval allows.
+type operationPollLimiter struct {
+	interval time.Duration
+	throttle bool
+
+	mutex     sync.Mutex
+	lastPoll  map[string]time.Time
+	lastSweep time.Time
+
+	nowImpl func() time.Time
+}
+
+// newOperationPollLimiter returns an operationPollLimiter for the given
+// minimum polling interval, or nil if the interval is not positive. If
+// throttle is true, polls arriving sooner than the interval are rejected.
+func newOperationPollLimiter(interval time.Duration, throttle bool) *operationPollLimiter {
+	if interval <= 0 {
+		return nil
+	}
+	return &operationPollLimiter{
+		interval: interval,
+		throttle: throttle,
+		lastPoll: make(map[string]time.Time),
+		nowImpl:  time.Now,
+	}
+}
+
+// Allow records a poll of the given key and returns false if the previous
+// poll of the same key was less than the interval ago. The key identifies
+// both the operation and the endpoint polled, so that polls of an
+// operation's status and of its result are limited independently.
+func (l *operationPollLimiter) Allow(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// Resource IDs are case-insensitive.
+	key = strings.ToLower(key)
+	now := l.nowImpl()
+
+	// Entries expire after one interval, so sweeping more than once
+	// per interval would only rescan entries that cannot yet expire.
+	if len(l.lastPoll) > operationPollSweepThreshold && now.Sub(l.lastSweep) >= l.interval {
+		for id, last := range l.lastPoll {
+			if now.Sub(last) >= l.interval {
+				delete(l.lastPoll, id)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	last, ok := l.lastPoll[key]
+	if ok && now.Sub(last) < l.interval {
+		return false
+	}
+
+	l.lastPoll[key] = now
+	return true
+}
+
+// RetryAfterSeconds returns the minimum polling interval in whole seconds.
+func (l *operationPollLimiter) RetryAfterSeconds() int {
+	return int(math.Ceil(l.interval.Seconds()))
+}
+
+// SetRetryAfterHeader sets a "Retry-After" header to the minimum polling interval.
+func (l *operationPollLimiter) SetRetryAfterHeader(header http.Header) {
+	header.Set("Retry-After", strconv.Itoa(l.RetryAfterSeconds()))
+}
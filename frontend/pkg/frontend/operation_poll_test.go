@@ -0,0 +1,206 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestCheckOperationPollInterval(t *testing.T) {
+	tests := []struct {
+		name               string
+		interval           time.Duration
+		throttle           bool
+		status             arm.ProvisioningState
+		expectRetryAfter   string
+		expectedStatusCode []int
+	}{
+		{
+			name:               "Disabled",
+			interval:           0,
+			status:             arm.ProvisioningStateAccepted,
+			expectRetryAfter:   "",
+			expectedStatusCode: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:               "Retry-After only",
+			interval:           10 * time.Second,
+			throttle:           false,
+			status:             arm.ProvisioningStateAccepted,
+			expectRetryAfter:   "10",
+			expectedStatusCode: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:               "Throttled",
+			interval:           10 * time.Second,
+			throttle:           true,
+			status:             arm.ProvisioningStateAccepted,
+			expectRetryAfter:   "10",
+			expectedStatusCode: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name:               "Terminal operation",
+			interval:           10 * time.Second,
+			throttle:           true,
+			status:             arm.ProvisioningStateSucceeded,
+			expectRetryAfter:   "",
+			expectedStatusCode: []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+
+			f := &Frontend{
+				operationPollLimiter: newOperationPollLimiter(tt.interval, tt.throttle),
+			}
+			if f.operationPollLimiter != nil {
+				f.operationPollLimiter.nowImpl = func() time.Time { return now }
+			}
+
+			doc := database.NewOperationDocument(database.OperationRequestCreate, nil, ocm.InternalID{})
+			doc.Status = tt.status
+
+			statusID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/providers/" + api.ProviderNamespace + "/locations/eastus/" + api.OperationStatusResourceTypeName + "/" + doc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resultID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/providers/" + api.ProviderNamespace + "/locations/eastus/" + api.OperationResultResourceTypeName + "/" + doc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Poll rapidly three times, then once more after the interval.
+			for i, expectedStatusCode := range tt.expectedStatusCode {
+				if i == len(tt.expectedStatusCode)-1 {
+					now = now.Add(tt.interval)
+				} else {
+					now = now.Add(time.Second)
+				}
+
+				writer := httptest.NewRecorder()
+				if f.CheckOperationPollInterval(writer, statusID, doc) {
+					writer.WriteHeader(http.StatusOK)
+				}
+
+				if writer.Code != expectedStatusCode {
+					t.Errorf("poll %d: expected status code %d, got %d", i+1, expectedStatusCode, writer.Code)
+				}
+				if retryAfter := writer.Header().Get("Retry-After"); retryAfter != tt.expectRetryAfter {
+					t.Errorf("poll %d: expected Retry-After %q, got %q", i+1, tt.expectRetryAfter, retryAfter)
+				}
+			}
+
+			// Polling the operation result is limited
+			// independently of polling the operation status.
+			writer := httptest.NewRecorder()
+			if !f.CheckOperationPollInterval(writer, resultID, doc) {
+				t.Errorf("expected first poll of the operation result to be allowed, got status code %d", writer.Code)
+			}
+		})
+	}
+}
+
+func TestOperationStatusPollLimit(t *testing.T) {
+	const interval = 10 * time.Second
+
+	now := time.Now()
+
+	f := &Frontend{
+		dbClient:             database.NewCache(),
+		operationPollLimiter: newOperationPollLimiter(interval, true),
+	}
+	f.operationPollLimiter.nowImpl = func() time.Time { return now }
+
+	ctx := ContextWithLogger(context.Background(), testLogger)
+
+	doc := database.NewOperationDocument(database.OperationRequestCreate, nil, ocm.InternalID{})
+	doc.Status = arm.ProvisioningStateAccepted
+
+	statusID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/providers/" + api.ProviderNamespace + "/locations/eastus/" + api.OperationStatusResourceTypeName + "/" + doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only operations exposed to the client can be polled.
+	doc.OperationID = statusID
+	err = f.dbClient.CreateOperationDoc(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	poll := func() *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, statusID.String(), nil)
+		request = request.WithContext(ContextWithResourceID(ctx, statusID))
+		request.SetPathValue(PathSegmentSubscriptionID, statusID.SubscriptionID)
+		writer := httptest.NewRecorder()
+		f.OperationStatus(writer, request)
+		return writer
+	}
+
+	if writer := poll(); writer.Code != http.StatusOK {
+		t.Fatalf("expected status code %d for the first poll, got %d", http.StatusOK, writer.Code)
+	}
+
+	// Polling again within the interval exceeds the limit.
+	now = now.Add(time.Second)
+	writer := poll()
+	if writer.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status code %d for a poll past the limit, got %d", http.StatusTooManyRequests, writer.Code)
+	}
+	if retryAfter := writer.Header().Get("Retry-After"); retryAfter != "10" {
+		t.Errorf("expected Retry-After %q, got %q", "10", retryAfter)
+	}
+	var cloudError arm.CloudError
+	err = json.Unmarshal(writer.Body.Bytes(), &cloudError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cloudError.Code != arm.CloudErrorCodeTooManyRequests {
+		t.Errorf("expected error code %s, got %s", arm.CloudErrorCodeTooManyRequests, cloudError.Code)
+	}
+
+	// Polling is allowed again once the interval has passed.
+	now = now.Add(interval)
+	if writer := poll(); writer.Code != http.StatusOK {
+		t.Errorf("expected status code %d after the interval, got %d", http.StatusOK, writer.Code)
+	}
+}
+
+func TestOperationPollLimiterSweep(t *testing.T) {
+	now := time.Now()
+
+	limiter := newOperationPollLimiter(10*time.Second, true)
+	limiter.nowImpl = func() time.Time { return now }
+
+	for i := 0; i <= operationPollSweepThreshold; i++ {
+		limiter.Allow(fmt.Sprintf("operation-%d", i))
+	}
+
+	// Nothing has expired yet, so the poll history keeps growing.
+	now = now.Add(time.Second)
+	limiter.Allow("operation-new")
+	if len(limiter.lastPoll) != operationPollSweepThreshold+2 {
+		t.Fatalf("expected %d tracked operations, got %d", operationPollSweepThreshold+2, len(limiter.lastPoll))
+	}
+
+	// Once the interval has passed, expired entries are swept.
+	now = now.Add(10 * time.Second)
+	limiter.Allow("operation-newer")
+	if len(limiter.lastPoll) != 1 {
+		t.Fatalf("expected 1 tracked operation after sweeping, got %d", len(limiter.lastPoll))
+	}
+}
@@ -150,6 +150,32 @@ func (f *Frontend) CancelActiveOperation(ctx context.Context, resourceDoc *datab
 	return nil
 }
 
+// CheckOperationPollInterval advertises the minimum polling interval for
+// an operation that has not yet reached a terminal state by way of a
+// "Retry-After" header. If polling is throttled and the operation was
+// polled again too soon, it writes a 429 Too Many Requests response and
+// returns false. The resource ID is that of the operation status or
+// result being polled.
+func (f *Frontend) CheckOperationPollInterval(writer http.ResponseWriter, resourceID *arm.ResourceID, doc *database.OperationDocument) bool {
+	limiter := f.operationPollLimiter
+	if limiter == nil || doc.Status.IsTerminal() {
+		return true
+	}
+
+	limiter.SetRetryAfterHeader(writer.Header())
+
+	if !limiter.Allow(resourceID.String()) && limiter.throttle {
+		arm.WriteError(
+			writer, http.StatusTooManyRequests,
+			arm.CloudErrorCodeTooManyRequests, "",
+			"Operation '%s' is being polled too frequently. Retry after %d seconds.",
+			doc.ID, limiter.RetryAfterSeconds())
+		return false
+	}
+
+	return true
+}
+
 // OperationIsVisible returns true if the request is being called from the same
 // tenant and subscription that the operation originated in.
 func (f *Frontend) OperationIsVisible(request *http.Request, doc *database.OperationDocument) bool {
@@ -46,7 +46,8 @@ func (f *Frontend) routes() *MiddlewareMux {
 	mux := NewMiddlewareMux(
 		MiddlewarePanic,
 		MiddlewareLogging,
-		MiddlewareBody,
+		MiddlewareTracing,
+		NewMiddlewareBody(f.maxRequestBodySize),
 		MiddlewareLowercase,
 		MiddlewareSystemData,
 		MiddlewareValidateStatic,
@@ -78,6 +79,7 @@ func (f *Frontend) routes() *MiddlewareMux {
 		MiddlewareResourceID,
 		MiddlewareLoggingPostMux,
 		MiddlewareValidateAPIVersion,
+		NewMiddlewareSubscriptionLimit(f.maxSubscriptionRequests),
 		MiddlewareLockSubscription,
 		MiddlewareValidateSubscriptionState)
 	mux.Handle(
@@ -120,6 +122,25 @@ func (f *Frontend) routes() *MiddlewareMux {
 	mux.Handle(
 		MuxPattern(http.MethodGet, PatternSubscriptions, PatternProviders, PatternLocations, PatternOperationsStatus),
 		postMuxMiddleware.HandlerFunc(f.OperationStatus))
+	postMuxMiddleware = NewMiddleware(
+		MiddlewareResourceID,
+		MiddlewareLoggingPostMux,
+		MiddlewareValidateAPIVersion,
+		MiddlewareLockSubscription,
+		MiddlewareValidateSubscriptionState)
+	mux.Handle(
+		MuxPattern(http.MethodPost, PatternSubscriptions, PatternProviders, PatternLocations, PatternOperationsStatus, OperationActionCancel),
+		postMuxMiddleware.HandlerFunc(f.OperationCancel))
+
+	// Admin endpoints
+	// These are not ARM resource actions so skip API version validation,
+	// and they must work regardless of the subscription state.
+	postMuxMiddleware = NewMiddleware(
+		MiddlewareLoggingPostMux,
+		MiddlewareLockSubscription)
+	mux.Handle(
+		MuxPattern(http.MethodPost, PatternSubscriptions, PatternResourceGroups, PatternProviders, PatternClusters, AdminActionReconcile),
+		postMuxMiddleware.HandlerFunc(f.AdminClusterReconcile))
 
 	// Exclude ARO-HCP API version validation for the following endpoints defined by ARM.
 
@@ -135,6 +156,14 @@ func (f *Frontend) routes() *MiddlewareMux {
 		MuxPattern(http.MethodPut, PatternSubscriptions),
 		postMuxMiddleware.HandlerFunc(f.ArmSubscriptionPut))
 
+	// Subscription features endpoint
+	postMuxMiddleware = NewMiddleware(
+		MiddlewareLoggingPostMux,
+		MiddlewareValidateSubscriptionState)
+	mux.Handle(
+		MuxPattern(http.MethodGet, PatternSubscriptions, PatternProviders, SubscriptionFeaturesPathSegment),
+		postMuxMiddleware.HandlerFunc(f.SubscriptionFeaturesGet))
+
 	// Deployment preflight endpoint
 	postMuxMiddleware = NewMiddleware(
 		MiddlewareLoggingPostMux,
@@ -143,6 +172,14 @@ func (f *Frontend) routes() *MiddlewareMux {
 		MuxPattern(http.MethodPost, PatternSubscriptions, PatternResourceGroups, "providers", api.ProviderNamespace, PatternDeployments, "preflight"),
 		postMuxMiddleware.HandlerFunc(f.ArmDeploymentPreflight))
 
+	// Resource move validation endpoint
+	postMuxMiddleware = NewMiddleware(
+		MiddlewareLoggingPostMux,
+		MiddlewareValidateSubscriptionState)
+	mux.Handle(
+		MuxPattern(http.MethodPost, PatternSubscriptions, PatternResourceGroups, "validateMoveResources"),
+		postMuxMiddleware.HandlerFunc(f.ArmValidateMoveResources))
+
 	return mux
 }
 
@@ -0,0 +1,187 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+	"github.com/Azure/ARO-HCP/internal/tracing"
+)
+
+const tracerName = "github.com/Azure/ARO-HCP/frontend"
+
+// tracingDBClient wraps a database.DBClient with a span around each
+// database transaction. Listing methods return lazy iterators, so they
+// are passed through untraced.
+type tracingDBClient struct {
+	database.DBClient
+}
+
+func newTracingDBClient(dbClient database.DBClient) database.DBClient {
+	return &tracingDBClient{DBClient: dbClient}
+}
+
+func (c *tracingDBClient) GetResourceDoc(ctx context.Context, resourceID *arm.ResourceID) (*database.ResourceDocument, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.GetResourceDoc", attribute.String("aro.resource_id", resourceID.String()))
+	doc, err := c.DBClient.GetResourceDoc(ctx, resourceID)
+	tracing.EndSpan(span, err)
+	return doc, err
+}
+
+func (c *tracingDBClient) GetResourceDocByInternalID(ctx context.Context, internalID ocm.InternalID) (*database.ResourceDocument, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.GetResourceDocByInternalID", attribute.String("aro.internal_id", internalID.String()))
+	doc, err := c.DBClient.GetResourceDocByInternalID(ctx, internalID)
+	tracing.EndSpan(span, err)
+	return doc, err
+}
+
+func (c *tracingDBClient) GetResourceDocs(ctx context.Context, internalIDs []ocm.InternalID) (map[ocm.InternalID]*database.ResourceDocument, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.GetResourceDocs", attribute.Int("aro.internal_id_count", len(internalIDs)))
+	docs, err := c.DBClient.GetResourceDocs(ctx, internalIDs)
+	tracing.EndSpan(span, err)
+	return docs, err
+}
+
+func (c *tracingDBClient) CreateResourceDoc(ctx context.Context, doc *database.ResourceDocument) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.CreateResourceDoc", attribute.String("aro.resource_id", doc.Key.String()))
+	err := c.DBClient.CreateResourceDoc(ctx, doc)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingDBClient) UpdateResourceDoc(ctx context.Context, resourceID *arm.ResourceID, callback func(*database.ResourceDocument) bool) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.UpdateResourceDoc", attribute.String("aro.resource_id", resourceID.String()))
+	updated, err := c.DBClient.UpdateResourceDoc(ctx, resourceID, callback)
+	tracing.EndSpan(span, err)
+	return updated, err
+}
+
+func (c *tracingDBClient) DeleteResourceDoc(ctx context.Context, resourceID *arm.ResourceID) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.DeleteResourceDoc", attribute.String("aro.resource_id", resourceID.String()))
+	err := c.DBClient.DeleteResourceDoc(ctx, resourceID)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingDBClient) GetOperationDoc(ctx context.Context, operationID string) (*database.OperationDocument, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.GetOperationDoc", attribute.String("aro.operation_id", operationID))
+	doc, err := c.DBClient.GetOperationDoc(ctx, operationID)
+	tracing.EndSpan(span, err)
+	return doc, err
+}
+
+func (c *tracingDBClient) CreateOperationDoc(ctx context.Context, doc *database.OperationDocument) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.CreateOperationDoc", attribute.String("aro.operation_id", doc.ID))
+	err := c.DBClient.CreateOperationDoc(ctx, doc)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingDBClient) UpdateOperationDoc(ctx context.Context, operationID string, callback func(*database.OperationDocument) bool) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.UpdateOperationDoc", attribute.String("aro.operation_id", operationID))
+	updated, err := c.DBClient.UpdateOperationDoc(ctx, operationID, callback)
+	tracing.EndSpan(span, err)
+	return updated, err
+}
+
+func (c *tracingDBClient) DeleteOperationDoc(ctx context.Context, operationID string) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.DeleteOperationDoc", attribute.String("aro.operation_id", operationID))
+	err := c.DBClient.DeleteOperationDoc(ctx, operationID)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingDBClient) GetSubscriptionDoc(ctx context.Context, subscriptionID string) (*database.SubscriptionDocument, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.GetSubscriptionDoc", attribute.String("aro.subscription_id", subscriptionID))
+	doc, err := c.DBClient.GetSubscriptionDoc(ctx, subscriptionID)
+	tracing.EndSpan(span, err)
+	return doc, err
+}
+
+func (c *tracingDBClient) CreateSubscriptionDoc(ctx context.Context, doc *database.SubscriptionDocument) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.CreateSubscriptionDoc", attribute.String("aro.subscription_id", doc.ID))
+	err := c.DBClient.CreateSubscriptionDoc(ctx, doc)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingDBClient) UpdateSubscriptionDoc(ctx context.Context, subscriptionID string, callback func(*database.SubscriptionDocument) bool) (bool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "database.UpdateSubscriptionDoc", attribute.String("aro.subscription_id", subscriptionID))
+	updated, err := c.DBClient.UpdateSubscriptionDoc(ctx, subscriptionID, callback)
+	tracing.EndSpan(span, err)
+	return updated, err
+}
+
+// tracingClusterServiceClient wraps an ocm.ClusterServiceClientSpec with
+// a span around each Cluster Service call. Listing methods return lazy
+// iterators, so they are passed through untraced.
+type tracingClusterServiceClient struct {
+	ocm.ClusterServiceClientSpec
+}
+
+func newTracingClusterServiceClient(csClient ocm.ClusterServiceClientSpec) ocm.ClusterServiceClientSpec {
+	return &tracingClusterServiceClient{ClusterServiceClientSpec: csClient}
+}
+
+func (c *tracingClusterServiceClient) GetCSCluster(ctx context.Context, internalID ocm.InternalID) (*cmv1.Cluster, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.GetCSCluster", attribute.String("aro.internal_id", internalID.String()))
+	cluster, err := c.ClusterServiceClientSpec.GetCSCluster(ctx, internalID)
+	tracing.EndSpan(span, err)
+	return cluster, err
+}
+
+func (c *tracingClusterServiceClient) PostCSCluster(ctx context.Context, cluster *cmv1.Cluster) (*cmv1.Cluster, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.PostCSCluster")
+	cluster, err := c.ClusterServiceClientSpec.PostCSCluster(ctx, cluster)
+	tracing.EndSpan(span, err)
+	return cluster, err
+}
+
+func (c *tracingClusterServiceClient) UpdateCSCluster(ctx context.Context, internalID ocm.InternalID, cluster *cmv1.Cluster) (*cmv1.Cluster, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.UpdateCSCluster", attribute.String("aro.internal_id", internalID.String()))
+	cluster, err := c.ClusterServiceClientSpec.UpdateCSCluster(ctx, internalID, cluster)
+	tracing.EndSpan(span, err)
+	return cluster, err
+}
+
+func (c *tracingClusterServiceClient) DeleteCSCluster(ctx context.Context, internalID ocm.InternalID) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.DeleteCSCluster", attribute.String("aro.internal_id", internalID.String()))
+	err := c.ClusterServiceClientSpec.DeleteCSCluster(ctx, internalID)
+	tracing.EndSpan(span, err)
+	return err
+}
+
+func (c *tracingClusterServiceClient) GetCSNodePool(ctx context.Context, internalID ocm.InternalID) (*cmv1.NodePool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.GetCSNodePool", attribute.String("aro.internal_id", internalID.String()))
+	nodePool, err := c.ClusterServiceClientSpec.GetCSNodePool(ctx, internalID)
+	tracing.EndSpan(span, err)
+	return nodePool, err
+}
+
+func (c *tracingClusterServiceClient) PostCSNodePool(ctx context.Context, clusterInternalID ocm.InternalID, nodePool *cmv1.NodePool) (*cmv1.NodePool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.PostCSNodePool", attribute.String("aro.internal_id", clusterInternalID.String()))
+	nodePool, err := c.ClusterServiceClientSpec.PostCSNodePool(ctx, clusterInternalID, nodePool)
+	tracing.EndSpan(span, err)
+	return nodePool, err
+}
+
+func (c *tracingClusterServiceClient) UpdateCSNodePool(ctx context.Context, internalID ocm.InternalID, nodePool *cmv1.NodePool) (*cmv1.NodePool, error) {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.UpdateCSNodePool", attribute.String("aro.internal_id", internalID.String()))
+	nodePool, err := c.ClusterServiceClientSpec.UpdateCSNodePool(ctx, internalID, nodePool)
+	tracing.EndSpan(span, err)
+	return nodePool, err
+}
+
+func (c *tracingClusterServiceClient) DeleteCSNodePool(ctx context.Context, internalID ocm.InternalID) error {
+	ctx, span := tracing.StartSpan(ctx, tracerName, "clusterservice.DeleteCSNodePool", attribute.String("aro.internal_id", internalID.String()))
+	err := c.ClusterServiceClientSpec.DeleteCSNodePool(ctx, internalID)
+	tracing.EndSpan(span, err)
+	return err
+}
@@ -0,0 +1,90 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/ARO-HCP/internal/api"
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+// openShiftVersionPrefix is the prefix Cluster Service uses for version IDs.
+const openShiftVersionPrefix = "openshift-v"
+
+// parseMajorMinor extracts the major and minor version numbers from an
+// OpenShift version ID such as "openshift-v4.16.0" or "4.16.0".
+func parseMajorMinor(versionID string) (major, minor int, ok bool) {
+	versionID = strings.TrimPrefix(strings.ToLower(versionID), openShiftVersionPrefix)
+	parts := strings.SplitN(versionID, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// checkVersionSkew returns an error if a node pool at nodePoolVersion may not
+// run under a control plane at clusterVersion. Node pools may lag behind the
+// control plane by at most maxSkew minor versions and may never be ahead of
+// it. Versions that cannot be parsed are not checked.
+func checkVersionSkew(clusterVersion, nodePoolVersion string, maxSkew int) error {
+	clusterMajor, clusterMinor, ok := parseMajorMinor(clusterVersion)
+	if !ok {
+		return nil
+	}
+	nodePoolMajor, nodePoolMinor, ok := parseMajorMinor(nodePoolVersion)
+	if !ok {
+		return nil
+	}
+
+	skew := clusterMinor - nodePoolMinor
+	if nodePoolMajor != clusterMajor || skew < 0 || skew > maxSkew {
+		return fmt.Errorf(
+			"Node pool version '%s' is not compatible with cluster version '%s' "+
+				"(node pools must be at most %d minor versions older than the cluster, and not newer)",
+			nodePoolVersion, clusterVersion, maxSkew)
+	}
+	return nil
+}
+
+// CheckNodePoolVersionSkew verifies a new node pool's version against the
+// version of its parent cluster in Cluster Service. Skew checking is disabled
+// if the maximum allowed skew is negative.
+func (f *Frontend) CheckNodePoolVersionSkew(ctx context.Context, clusterDoc *database.ResourceDocument, nodePool *api.HCPOpenShiftClusterNodePool) *arm.CloudError {
+	logger := LoggerFromContext(ctx)
+
+	if f.maxNodePoolVersionSkew < 0 {
+		return nil
+	}
+
+	csCluster, err := f.clusterServiceClient.GetCSCluster(ctx, clusterDoc.InternalID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to fetch CS cluster for %s: %v", clusterDoc.Key, err))
+		return CSErrorToCloudError(err, nil)
+	}
+
+	err = checkVersionSkew(csCluster.Version().ID(), nodePool.Properties.Spec.Version.ID, f.maxNodePoolVersionSkew)
+	if err != nil {
+		return arm.NewCloudError(
+			http.StatusBadRequest,
+			arm.CloudErrorCodeInvalidRequestContent,
+			"properties.spec.version.id",
+			"%s", err.Error())
+	}
+
+	return nil
+}
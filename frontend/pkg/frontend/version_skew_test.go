@@ -0,0 +1,82 @@
+package frontend
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"testing"
+)
+
+func TestCheckVersionSkew(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterVersion  string
+		nodePoolVersion string
+		maxSkew         int
+		expectError     bool
+	}{
+		{
+			name:            "Same version",
+			clusterVersion:  "openshift-v4.16.3",
+			nodePoolVersion: "openshift-v4.16.0",
+			maxSkew:         2,
+		},
+		{
+			name:            "Within skew",
+			clusterVersion:  "openshift-v4.16.3",
+			nodePoolVersion: "openshift-v4.14.10",
+			maxSkew:         2,
+		},
+		{
+			name:            "Beyond skew",
+			clusterVersion:  "openshift-v4.16.3",
+			nodePoolVersion: "openshift-v4.13.0",
+			maxSkew:         2,
+			expectError:     true,
+		},
+		{
+			name:            "Newer than cluster",
+			clusterVersion:  "openshift-v4.15.0",
+			nodePoolVersion: "openshift-v4.16.0",
+			maxSkew:         2,
+			expectError:     true,
+		},
+		{
+			name:            "Different major version",
+			clusterVersion:  "openshift-v5.0.0",
+			nodePoolVersion: "openshift-v4.16.0",
+			maxSkew:         2,
+			expectError:     true,
+		},
+		{
+			name:            "Zero skew requires same minor version",
+			clusterVersion:  "4.16.0",
+			nodePoolVersion: "4.15.0",
+			maxSkew:         0,
+			expectError:     true,
+		},
+		{
+			name:            "Unparseable cluster version is not checked",
+			clusterVersion:  "",
+			nodePoolVersion: "openshift-v4.10.0",
+			maxSkew:         2,
+		},
+		{
+			name:            "Unparseable node pool version is not checked",
+			clusterVersion:  "openshift-v4.16.0",
+			nodePoolVersion: "latest",
+			maxSkew:         2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkVersionSkew(tt.clusterVersion, tt.nodePoolVersion, tt.maxSkew)
+			if tt.expectError && err == nil {
+				t.Error("expected an error, got none")
+			} else if !tt.expectError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 
 	azcorearm "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 )
@@ -29,6 +30,11 @@ const (
 	CloudErrorCodeInvalidSubscriptionID    = "InvalidSubscriptionID"
 	CloudErrorCodeInvalidResourceName      = "InvalidResourceName"
 	CloudErrorCodeInvalidResourceGroupName = "InvalidResourceGroupName"
+	CloudErrorCodePreconditionFailed       = "PreconditionFailed"
+	CloudErrorCodeTooManyRequests          = "TooManyRequests"
+	CloudErrorCodeForbidden                = "Forbidden"
+	CloudErrorCodePayloadTooLarge          = "PayloadTooLarge"
+	CloudErrorCodeResourceMoveNotSupported = "ResourceMoveNotSupported"
 )
 
 // CloudError represents a complete resource provider error.
@@ -153,6 +159,10 @@ func WriteResourceNotFoundError(w http.ResponseWriter, resourceID *ResourceID) {
 	WriteCloudError(w, NewResourceNotFoundError(resourceID))
 }
 
+// unknownFieldRegexp extracts the property name from the error returned by
+// the generated API models when a request body has a property they lack.
+var unknownFieldRegexp = regexp.MustCompile(`unknown field "([^"]*)"`)
+
 // NewInvalidRequestContentError creates a CloudError for an invalid request content error
 func NewInvalidRequestContentError(err error) *CloudError {
 	const message = "The request content was invalid and could not be deserialized: %q"
@@ -166,6 +176,15 @@ func NewInvalidRequestContentError(err error) *CloudError {
 			CloudErrorCodeInvalidRequestContent,
 			err.Field, message, err)
 	default:
+		// The generated models reject unknown properties, such as
+		// misspelled property names, but only report them in text.
+		if match := unknownFieldRegexp.FindStringSubmatch(err.Error()); match != nil {
+			return NewCloudError(
+				http.StatusBadRequest,
+				CloudErrorCodeInvalidRequestContent,
+				match[1],
+				"The request content has an unknown property '%s'", match[1])
+		}
 		return NewCloudError(
 			http.StatusBadRequest,
 			CloudErrorCodeInvalidRequestContent,
@@ -177,3 +196,13 @@ func NewInvalidRequestContentError(err error) *CloudError {
 func WriteInvalidRequestContentError(w http.ResponseWriter, err error) {
 	WriteCloudError(w, NewInvalidRequestContentError(err))
 }
+
+// NewPreconditionFailedError creates a CloudError for a failed conditional request
+func NewPreconditionFailedError(resourceID *ResourceID, header string) *CloudError {
+	return NewCloudError(
+		http.StatusPreconditionFailed,
+		CloudErrorCodePreconditionFailed,
+		resourceID.String(),
+		"The condition specified by the '%s' header was not met.",
+		header)
+}
@@ -1,6 +1,10 @@
 package arm
 
-import "testing"
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
 
 func TestCloudErrorBody_String(t *testing.T) {
 	tests := []struct {
@@ -58,3 +62,43 @@ func TestCloudErrorBody_String(t *testing.T) {
 		})
 	}
 }
+
+func TestNewInvalidRequestContentError(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedTarget  string
+		expectedMessage string
+	}{
+		{
+			name:            "Misspelled property",
+			err:             errors.New(`unmarshalling type *v20240610preview.HcpOpenShiftClusterResource: unmarshalling type *generated.HcpOpenShiftClusterResource, unknown field "properteis"`),
+			expectedTarget:  "properteis",
+			expectedMessage: "The request content has an unknown property 'properteis'",
+		},
+		{
+			name:            "Malformed JSON",
+			err:             errors.New("unexpected end of JSON input"),
+			expectedTarget:  "",
+			expectedMessage: `The request content was invalid and could not be deserialized: "unexpected end of JSON input"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cloudError := NewInvalidRequestContentError(tt.err)
+			if cloudError.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected status code %d, got %d", http.StatusBadRequest, cloudError.StatusCode)
+			}
+			if cloudError.Code != CloudErrorCodeInvalidRequestContent {
+				t.Errorf("expected code %s, got %s", CloudErrorCodeInvalidRequestContent, cloudError.Code)
+			}
+			if cloudError.Target != tt.expectedTarget {
+				t.Errorf("expected target '%s', got '%s'", tt.expectedTarget, cloudError.Target)
+			}
+			if cloudError.Message != tt.expectedMessage {
+				t.Errorf("expected message '%s', got '%s'", tt.expectedMessage, cloudError.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package arm
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"encoding/json"
+)
+
+// See the "Move Resource" section of the ARM resource provider contract.
+
+// MoveResources represents the body of a move resources or validate move
+// resources request.
+type MoveResources struct {
+	// TargetResourceGroup is the resource ID of the destination resource group
+	TargetResourceGroup string `json:"targetResourceGroup"`
+	// Resources are the IDs of the resources to move
+	Resources []string `json:"resources"`
+}
+
+// UnmarshalMoveResources unmarshals JSON-encoded data and returns either
+// a MoveResources instance or an appropriate CloudError with a 400 Bad
+// Request HTTP status code.
+func UnmarshalMoveResources(data []byte) (*MoveResources, *CloudError) {
+	moveResources := &MoveResources{}
+	err := json.Unmarshal(data, moveResources)
+	if err != nil {
+		return nil, NewInvalidRequestContentError(err)
+	}
+	return moveResources, nil
+}
@@ -0,0 +1,43 @@
+package api
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalCanonicalJSON returns a canonical JSON encoding of v: compact, with
+// the keys of every object sorted, including those of marshaled structs, and
+// without HTML escaping. Equal values always produce identical bytes, which
+// makes the output suitable for golden tests and for computing stable hashes
+// of resources, regardless of struct field order or custom marshalers.
+func MarshalCanonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode into generic values so objects become maps, which
+	// encoding/json always encodes with sorted keys. UseNumber
+	// preserves numbers exactly as originally encoded.
+	var generic any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&generic)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(generic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encode appends a newline.
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
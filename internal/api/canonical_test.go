@@ -0,0 +1,77 @@
+package api
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name: "Struct fields are sorted",
+			value: struct {
+				Zulu  string `json:"zulu"`
+				Alpha int    `json:"alpha"`
+			}{Zulu: "z", Alpha: 1},
+			expected: `{"alpha":1,"zulu":"z"}`,
+		},
+		{
+			name: "Nested objects are sorted",
+			value: map[string]any{
+				"b": []any{map[string]any{"y": 1, "x": 2}},
+				"a": map[string]any{"d": true, "c": nil},
+			},
+			expected: `{"a":{"c":null,"d":true},"b":[{"x":2,"y":1}]}`,
+		},
+		{
+			name:     "Numbers and HTML characters are preserved",
+			value:    map[string]any{"n": 12345678901234567, "s": "<a&b>"},
+			expected: `{"n":12345678901234567,"s":"<a&b>"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := MarshalCanonicalJSON(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, data)
+			}
+		})
+	}
+}
+
+func TestMarshalCanonicalJSONStable(t *testing.T) {
+	cluster := minimumValidCluster()
+	cluster.Name = "testCluster"
+	cluster.Tags = map[string]string{"c": "3", "a": "1", "b": "2"}
+	cluster.Properties.Spec.Platform.OperatorsAuthentication.UserAssignedIdentities.ControlPlaneOperators = map[string]string{
+		"ingress":        "/identity/ingress",
+		"cloud-provider": "/identity/cloud-provider",
+		"disk-csi":       "/identity/disk-csi",
+	}
+
+	expected, err := MarshalCanonicalJSON(cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 10 {
+		data, err := MarshalCanonicalJSON(cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, expected) {
+			t.Fatalf("Expected byte-stable output:\n%s\ngot:\n%s", expected, data)
+		}
+	}
+}
@@ -4,6 +4,11 @@ package api
 // Licensed under the Apache License 2.0.
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
 	configv1 "github.com/openshift/api/config/v1"
 
 	"github.com/Azure/ARO-HCP/internal/api/arm"
@@ -85,7 +90,7 @@ type ProxyProfile struct {
 // PlatformProfile represents the Azure platform configuration.
 // Visibility for the entire struct is "read create".
 type PlatformProfile struct {
-	ManagedResourceGroup    string                         `json:"managedResourceGroup,omitempty"`
+	ManagedResourceGroup    string                         `json:"managedResourceGroup,omitempty" validate:"omitempty,resource_group_name"`
 	SubnetID                string                         `json:"subnetId,omitempty"             validate:"required_for_put"`
 	OutboundType            OutboundType                   `json:"outboundType,omitempty"         validate:"omitempty,enum_outboundtype"`
 	NetworkSecurityGroupID  string                         `json:"networkSecurityGroupId,omitempty"`
@@ -126,3 +131,102 @@ func NewDefaultHCPOpenShiftCluster() *HCPOpenShiftCluster {
 		},
 	}
 }
+
+// ClusterDifferences returns human-readable descriptions of the changes to
+// the tags and to the updatable spec fields of a cluster. An empty result
+// means an update from oldCluster to newCluster would change nothing.
+func ClusterDifferences(oldCluster, newCluster *HCPOpenShiftCluster) []string {
+	return append(
+		ClusterTagDifferences(oldCluster, newCluster),
+		ClusterSpecDifferences(oldCluster, newCluster)...)
+}
+
+// ClusterTagDifferences returns human-readable descriptions of the changes
+// to the tags of a cluster. A nil Tags map in the new cluster means the tags
+// are left alone, whereas an empty map removes all tags.
+func ClusterTagDifferences(oldCluster, newCluster *HCPOpenShiftCluster) []string {
+	var messages []string
+
+	if newCluster.Tags != nil {
+		for key, oldValue := range oldCluster.Tags {
+			newValue, exists := newCluster.Tags[key]
+			if !exists {
+				messages = append(messages, fmt.Sprintf("Tag %s removed", key))
+			} else if oldValue != newValue {
+				messages = append(messages, fmt.Sprintf("Tag %s changed from '%s' to '%s'", key, oldValue, newValue))
+			}
+		}
+		for key, newValue := range newCluster.Tags {
+			if _, exists := oldCluster.Tags[key]; !exists {
+				messages = append(messages, fmt.Sprintf("Tag %s added with value '%s'", key, newValue))
+			}
+		}
+	}
+
+	// Map iteration order is random.
+	slices.Sort(messages)
+
+	return messages
+}
+
+// ClusterSpecDifferences returns human-readable descriptions of the changes
+// to the fields of a cluster spec that can be updated, as determined by
+// their visibility struct tags. Credentials in URL values are redacted, and
+// multi-line values such as certificates are not repeated.
+func ClusterSpecDifferences(oldCluster, newCluster *HCPOpenShiftCluster) []string {
+	var messages []string
+
+	specField, _ := reflect.TypeFor[HCPOpenShiftClusterProperties]().FieldByName("Spec")
+	flags, _ := GetVisibilityFlags(specField.Tag)
+
+	appendDifferences(&messages,
+		reflect.ValueOf(oldCluster.Properties.Spec),
+		reflect.ValueOf(newCluster.Properties.Spec),
+		"", flags)
+
+	slices.Sort(messages)
+
+	return messages
+}
+
+// appendDifferences appends a message for each updatable field that differs
+// between oldVal and newVal. Fields without a visibility struct tag inherit
+// the visibility of their parent.
+func appendDifferences(messages *[]string, oldVal, newVal reflect.Value, path string, implicitVisibility VisibilityFlags) {
+	if newVal.Kind() == reflect.Struct {
+		for i := 0; i < newVal.NumField(); i++ {
+			field := newVal.Type().Field(i)
+			flags, ok := GetVisibilityFlags(field.Tag)
+			if !ok {
+				flags = implicitVisibility
+			}
+			name := GetJSONTagName(field.Tag)
+			if name == "" {
+				name = field.Name
+			}
+			appendDifferences(messages, oldVal.Field(i), newVal.Field(i), join(path, name), flags)
+		}
+		return
+	}
+
+	if !implicitVisibility.CanUpdate() || reflect.DeepEqual(oldVal.Interface(), newVal.Interface()) {
+		return
+	}
+
+	switch newVal.Kind() {
+	case reflect.String:
+		oldStr, newStr := oldVal.String(), newVal.String()
+		if strings.Contains(oldStr, "\n") || strings.Contains(newStr, "\n") {
+			*messages = append(*messages, fmt.Sprintf("%s changed", path))
+		} else {
+			*messages = append(*messages, fmt.Sprintf("%s changed from '%s' to '%s'", path, redactURLCredentials(oldStr), redactURLCredentials(newStr)))
+		}
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		*messages = append(*messages, fmt.Sprintf("%s changed from %v to %v", path, oldVal.Interface(), newVal.Interface()))
+	default:
+		*messages = append(*messages, fmt.Sprintf("%s changed", path))
+	}
+}
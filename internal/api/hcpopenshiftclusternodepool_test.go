@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"dario.cat/mergo"
@@ -170,6 +171,135 @@ func TestNodePoolValidateTags(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Valid labels and taints",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Labels: map[string]string{
+							"app":                     "web",
+							"example.com/tier":        "front_end.v1",
+							"node-role.kubernetes.io": "",
+						},
+						Taints: []*Taint{
+							{Effect: EffectNoSchedule, Key: "dedicated", Value: "gpu"},
+							{Effect: EffectPreferNoSchedule, Key: "example.com/spot"},
+							{Effect: EffectNoExecute, Key: "maintenance", Value: "true"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Malformed label key",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Labels: map[string]string{
+							"-app": "web",
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value '-app' for field 'labels[-app]' (must be an optional DNS subdomain prefix and '/', followed by at most 63 alphanumerics, hyphens, underscores or periods that begin and end with an alphanumeric)",
+					Target:  "properties.spec.labels[-app]",
+				},
+			},
+		},
+		{
+			name: "Malformed label key prefix",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Labels: map[string]string{
+							"Example.com/app": "web",
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value 'Example.com/app' for field 'labels[Example.com/app]' (must be an optional DNS subdomain prefix and '/', followed by at most 63 alphanumerics, hyphens, underscores or periods that begin and end with an alphanumeric)",
+					Target:  "properties.spec.labels[Example.com/app]",
+				},
+			},
+		},
+		{
+			name: "Overlong label value",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Labels: map[string]string{
+							"app": strings.Repeat("a", 64),
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value '" + strings.Repeat("a", 64) + "' for field 'labels[app]' (must be at most 63 alphanumerics, hyphens, underscores or periods, and begin and end with an alphanumeric)",
+					Target:  "properties.spec.labels[app]",
+				},
+			},
+		},
+		{
+			name: "Bad taint effect",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Taints: []*Taint{
+							{Effect: EffectNoSchedule, Key: "dedicated"},
+							{Effect: EffectNoSchedule, Key: "spot"},
+							{Effect: "NoScale", Key: "maintenance"},
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value 'NoScale' for field 'effect' (must be one of: NoExecute NoSchedule PreferNoSchedule)",
+					Target:  "properties.spec.taints[2].effect",
+				},
+			},
+		},
+		{
+			name: "Malformed taint key",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Taints: []*Taint{
+							{Effect: EffectNoSchedule, Key: "dedicated/"},
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value 'dedicated/' for field 'key' (must be an optional DNS subdomain prefix and '/', followed by at most 63 alphanumerics, hyphens, underscores or periods that begin and end with an alphanumeric)",
+					Target:  "properties.spec.taints[0].key",
+				},
+			},
+		},
+		{
+			name: "Overlong taint value",
+			tweaks: &HCPOpenShiftClusterNodePool{
+				Properties: HCPOpenShiftClusterNodePoolProperties{
+					Spec: NodePoolSpec{
+						Taints: []*Taint{
+							{Effect: EffectNoSchedule, Key: "dedicated", Value: strings.Repeat("b", 64)},
+						},
+					},
+				},
+			},
+			expectErrors: []arm.CloudErrorBody{
+				{
+					Message: "Invalid value '" + strings.Repeat("b", 64) + "' for field 'value' (must be at most 63 alphanumerics, hyphens, underscores or periods, and begin and end with an alphanumeric)",
+					Target:  "properties.spec.taints[0].value",
+				},
+			},
+		},
 	}
 
 	// from hcpopenshiftcluster_test.go
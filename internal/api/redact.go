@@ -0,0 +1,67 @@
+package api
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"net/url"
+)
+
+// redactedValue replaces sensitive values that are masked entirely.
+const redactedValue = "xxxxx"
+
+// redactURLCredentials masks the userinfo and query portions of a URL, if
+// present. Both the user name and the password are masked since either may
+// be a secret, and query parameters may carry access tokens. Values that
+// fail to parse as a URL are returned unmodified.
+func redactURLCredentials(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return s
+	}
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redactedValue
+	}
+	return u.String()
+}
+
+// redactProxyURL is like redactURLCredentials but masks a value that fails
+// to parse as a URL entirely, since a proxy URL is expected to be a URL and
+// may still hold credentials.
+func redactProxyURL(s string) string {
+	if _, err := url.Parse(s); err != nil {
+		return redactedValue
+	}
+	return redactURLCredentials(s)
+}
+
+// RedactHCPOpenShiftCluster returns a copy of the given cluster with
+// sensitive field values masked, making it suitable for logging or for
+// echoing back to a caller for debugging purposes.
+func RedactHCPOpenShiftCluster(cluster *HCPOpenShiftCluster) *HCPOpenShiftCluster {
+	if cluster == nil {
+		return nil
+	}
+
+	redacted := *cluster
+
+	// Proxy URLs may embed basic authentication credentials or tokens.
+	if redacted.Properties.Spec.Proxy.HTTPProxy != "" {
+		redacted.Properties.Spec.Proxy.HTTPProxy = redactProxyURL(redacted.Properties.Spec.Proxy.HTTPProxy)
+	}
+	if redacted.Properties.Spec.Proxy.HTTPSProxy != "" {
+		redacted.Properties.Spec.Proxy.HTTPSProxy = redactProxyURL(redacted.Properties.Spec.Proxy.HTTPSProxy)
+	}
+
+	// The trusted CA bundle describes the customer's internal PKI and is
+	// too large to be useful in a debug response, so only its presence is
+	// shown.
+	if redacted.Properties.Spec.Proxy.TrustedCA != "" {
+		redacted.Properties.Spec.Proxy.TrustedCA = redactedValue
+	}
+
+	return &redacted
+}
@@ -5,6 +5,9 @@ package api
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 
 	azcorearm "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 
@@ -56,3 +59,68 @@ func Lookup(key string) (version Version, ok bool) {
 	version, ok = apiRegistry[key]
 	return
 }
+
+// apiVersionRegexp matches api-version parameter values, which are a date
+// with an optional suffix such as "-preview".
+var apiVersionRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(-[a-z]+)?$`)
+
+// compareAPIVersions returns a negative number if API version a is older
+// than b, a positive number if it is newer, and zero if they are the same.
+// Both must match apiVersionRegexp. Versions are ordered by date, and a
+// stable version is newer than a prerelease version of the same date.
+func compareAPIVersions(a, b string) int {
+	if c := strings.Compare(a[:10], b[:10]); c != 0 {
+		return c
+	}
+
+	aSuffix := a[10:]
+	bSuffix := b[10:]
+	switch {
+	case aSuffix == bSuffix:
+		return 0
+	case aSuffix == "":
+		return 1
+	case bSuffix == "":
+		return -1
+	default:
+		return strings.Compare(aSuffix, bSuffix)
+	}
+}
+
+// LatestVersion returns the newest registered API version, including
+// preview versions.
+func LatestVersion() (version Version, ok bool) {
+	var latest string
+	for key, v := range apiRegistry {
+		if !apiVersionRegexp.MatchString(key) {
+			continue
+		}
+		if latest == "" || compareAPIVersions(key, latest) > 0 {
+			latest = key
+			version = v
+		}
+	}
+	return version, latest != ""
+}
+
+// ListVersions returns the registered API versions from oldest to newest.
+func ListVersions() []string {
+	var versions []string
+	for key := range apiRegistry {
+		if apiVersionRegexp.MatchString(key) {
+			versions = append(versions, key)
+		}
+	}
+	slices.SortFunc(versions, compareAPIVersions)
+	return versions
+}
+
+// ResolveVersion looks up the API version requested by a client. If the
+// client did not request a version and allowLatest is true, the latest
+// registered version is returned instead.
+func ResolveVersion(key string, allowLatest bool) (version Version, ok bool) {
+	if key == "" && allowLatest {
+		return LatestVersion()
+	}
+	return Lookup(key)
+}
@@ -6,6 +6,7 @@ package api
 import (
 	"net/http"
 	"reflect"
+	"slices"
 	"testing"
 
 	validator "github.com/go-playground/validator/v10"
@@ -223,3 +224,133 @@ func TestNewValidator(t *testing.T) {
 		})
 	}
 }
+
+// testVersion is a Version that only has a name.
+type testVersion string
+
+func (v testVersion) String() string {
+	return string(v)
+}
+
+func (v testVersion) NewHCPOpenShiftCluster(*HCPOpenShiftCluster) VersionedHCPOpenShiftCluster {
+	return nil
+}
+
+func (v testVersion) NewHCPOpenShiftClusterNodePool(*HCPOpenShiftClusterNodePool) VersionedHCPOpenShiftClusterNodePool {
+	return nil
+}
+
+func TestCompareAPIVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{
+			name:     "Same version",
+			a:        "2024-06-10-preview",
+			b:        "2024-06-10-preview",
+			expected: 0,
+		},
+		{
+			name:     "Older date",
+			a:        "2024-06-10-preview",
+			b:        "2025-12-23-preview",
+			expected: -1,
+		},
+		{
+			name:     "Newer date",
+			a:        "2025-12-23-preview",
+			b:        "2024-06-10-preview",
+			expected: 1,
+		},
+		{
+			name:     "Newer preview than stable",
+			a:        "2025-12-23-preview",
+			b:        "2024-06-10",
+			expected: 1,
+		},
+		{
+			name:     "Stable newer than preview of same date",
+			a:        "2024-06-10",
+			b:        "2024-06-10-preview",
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := compareAPIVersions(tt.a, tt.b)
+			if max(min(actual, 1), -1) != tt.expected {
+				t.Errorf("Expected %d comparing '%s' to '%s', got %d", tt.expected, tt.a, tt.b, actual)
+			}
+		})
+	}
+}
+
+func TestLatestVersion(t *testing.T) {
+	savedRegistry := apiRegistry
+	t.Cleanup(func() { apiRegistry = savedRegistry })
+
+	apiRegistry = map[string]Version{}
+
+	_, ok := LatestVersion()
+	if ok {
+		t.Error("Expected no latest version with an empty registry")
+	}
+
+	// Registration order must not matter.
+	Register(testVersion("2025-12-23-preview"))
+	Register(testVersion("2024-06-10-preview"))
+	apiRegistry["valid-api-version"] = nil
+
+	version, ok := LatestVersion()
+	if !ok {
+		t.Fatal("Expected a latest version")
+	}
+	if version.String() != "2025-12-23-preview" {
+		t.Errorf("Expected latest version '2025-12-23-preview', got '%s'", version)
+	}
+
+	version, ok = ResolveVersion("2024-06-10-preview", true)
+	if !ok || version.String() != "2024-06-10-preview" {
+		t.Errorf("Expected requested version '2024-06-10-preview', got '%v'", version)
+	}
+
+	version, ok = ResolveVersion("", true)
+	if !ok || version.String() != "2025-12-23-preview" {
+		t.Errorf("Expected latest version '2025-12-23-preview', got '%v'", version)
+	}
+
+	_, ok = ResolveVersion("", false)
+	if ok {
+		t.Error("Expected no version when falling back to latest is not allowed")
+	}
+
+	_, ok = ResolveVersion("2023-01-01", true)
+	if ok {
+		t.Error("Expected no version for an unregistered API version")
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	savedRegistry := apiRegistry
+	t.Cleanup(func() { apiRegistry = savedRegistry })
+
+	apiRegistry = map[string]Version{}
+
+	if versions := ListVersions(); len(versions) != 0 {
+		t.Errorf("Expected no versions with an empty registry, got %v", versions)
+	}
+
+	Register(testVersion("2025-12-23"))
+	Register(testVersion("2025-12-23-preview"))
+	Register(testVersion("2024-06-10-preview"))
+	apiRegistry["valid-api-version"] = nil
+
+	expected := []string{"2024-06-10-preview", "2025-12-23-preview", "2025-12-23"}
+	if versions := ListVersions(); !slices.Equal(versions, expected) {
+		t.Errorf("Expected versions %v, got %v", expected, versions)
+	}
+}
@@ -339,6 +339,16 @@ func (c *HcpOpenShiftClusterResource) ValidateStatic(current api.VersionedHCPOpe
 		cloudError.Details = append(cloudError.Details, errorDetails...)
 	}
 
+	errorDetails = api.ValidateTags(normalized.Tags)
+	if errorDetails != nil {
+		cloudError.Details = append(cloudError.Details, errorDetails...)
+	}
+
+	errorDetails = api.ValidateProxy(&normalized.Properties.Spec.Proxy)
+	if errorDetails != nil {
+		cloudError.Details = append(cloudError.Details, errorDetails...)
+	}
+
 	switch len(cloudError.Details) {
 	case 0:
 		cloudError = nil
@@ -69,6 +69,9 @@ func (h *HcpOpenShiftClusterNodePoolResource) Normalize(out *api.HCPOpenShiftClu
 			normalizeNodePoolPlatform(h.Properties.Spec.Platform, &out.Properties.Spec.Platform)
 		}
 		if h.Properties.Spec.AutoScaling != nil {
+			if out.Properties.Spec.AutoScaling == nil {
+				out.Properties.Spec.AutoScaling = &api.NodePoolAutoScaling{}
+			}
 			if h.Properties.Spec.AutoScaling.Max != nil {
 				out.Properties.Spec.AutoScaling.Max = *h.Properties.Spec.AutoScaling.Max
 			}
@@ -158,6 +161,11 @@ func (h *HcpOpenShiftClusterNodePoolResource) ValidateStatic(current api.Version
 		cloudError.Details = append(cloudError.Details, errorDetails...)
 	}
 
+	errorDetails = api.ValidateTags(normalized.Tags)
+	if errorDetails != nil {
+		cloudError.Details = append(cloudError.Details, errorDetails...)
+	}
+
 	switch len(cloudError.Details) {
 	case 0:
 		cloudError = nil
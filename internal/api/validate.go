@@ -6,8 +6,13 @@ package api
 import (
 	"crypto/x509"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 	"unicode"
 
@@ -16,6 +21,46 @@ import (
 	"github.com/Azure/ARO-HCP/internal/api/arm"
 )
 
+// Azure resource group names may contain alphanumerics, underscores,
+// parentheses, hyphens, periods, and Unicode letters and digits. They
+// must be 1-90 characters long and cannot end with a period.
+var resourceGroupNameRegexp = regexp.MustCompile(`^[-\w._()\p{L}\p{N}]{0,89}[-\w()\p{L}\p{N}]$`)
+
+// Kubernetes label values and the name part of qualified names (label keys,
+// taint keys) consist of alphanumerics, hyphens, underscores and periods,
+// and must begin and end with an alphanumeric. The optional prefix of a
+// qualified name is a lowercase RFC 1123 DNS subdomain.
+var (
+	k8sQualifiedNameRegexp = regexp.MustCompile(`^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$`)
+	k8sDNSSubdomainRegexp  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+const (
+	k8sQualifiedNameMaxLength = 63
+	k8sDNSSubdomainMaxLength  = 253
+	k8sLabelValueMaxLength    = 63
+)
+
+// isK8sQualifiedName returns true if value is a valid Kubernetes qualified
+// name, as used for label keys and taint keys: an optional DNS subdomain
+// prefix and a slash, followed by a name of at most 63 characters.
+func isK8sQualifiedName(value string) bool {
+	name := value
+	if prefix, suffix, found := strings.Cut(value, "/"); found {
+		if len(prefix) == 0 || len(prefix) > k8sDNSSubdomainMaxLength || !k8sDNSSubdomainRegexp.MatchString(prefix) {
+			return false
+		}
+		name = suffix
+	}
+	return len(name) <= k8sQualifiedNameMaxLength && k8sQualifiedNameRegexp.MatchString(name)
+}
+
+// isK8sLabelValue returns true if value is a valid Kubernetes label value.
+// Label values may be empty.
+func isK8sLabelValue(value string) bool {
+	return value == "" || (len(value) <= k8sLabelValueMaxLength && k8sQualifiedNameRegexp.MatchString(value))
+}
+
 // GetJSONTagName extracts the JSON field name from the "json" key in
 // a struct tag. Returns an empty string if no "json" key is present,
 // or if the value is "-".
@@ -83,6 +128,44 @@ func NewValidator() *validator.Validate {
 		panic(err)
 	}
 
+	// Use this for string fields providing an Azure resource group name.
+	err = validate.RegisterValidation("resource_group_name", func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		if field.Kind() != reflect.String {
+			panic("String type required for resource_group_name")
+		}
+		return resourceGroupNameRegexp.MatchString(field.String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Use this for string fields providing a Kubernetes qualified name,
+	// such as a label key or a taint key.
+	err = validate.RegisterValidation("k8s_qualified_name", func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		if field.Kind() != reflect.String {
+			panic("String type required for k8s_qualified_name")
+		}
+		return isK8sQualifiedName(field.String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Use this for string fields providing a Kubernetes label value,
+	// such as a label value or a taint value.
+	err = validate.RegisterValidation("k8s_label_value", func(fl validator.FieldLevel) bool {
+		field := fl.Field()
+		if field.Kind() != reflect.String {
+			panic("String type required for k8s_label_value")
+		}
+		return isK8sLabelValue(field.String())
+	})
+	if err != nil {
+		panic(err)
+	}
+
 	// Use this for fields required in PUT requests. Do not apply to read-only fields.
 	err = validate.RegisterValidation("required_for_put", func(fl validator.FieldLevel) bool {
 		val := fl.Top().FieldByName("Method")
@@ -135,7 +218,12 @@ func ValidateRequest(validate *validator.Validate, method string, resource any)
 	switch err := err.(type) {
 	case validator.ValidationErrors:
 		for _, fieldErr := range err {
-			message := fmt.Sprintf("Invalid value '%v' for field '%s'", fieldErr.Value(), fieldErr.Field())
+			value := fieldErr.Value()
+			// URL values may embed credentials.
+			if s, ok := value.(string); ok && (fieldErr.Tag() == "url" || fieldErr.Tag() == "startswith") {
+				value = redactURLCredentials(s)
+			}
+			message := fmt.Sprintf("Invalid value '%v' for field '%s'", value, fieldErr.Field())
 			// Try to add a corrective suggestion to the message.
 			tag := fieldErr.Tag()
 			if strings.HasPrefix(tag, "enum_") {
@@ -148,8 +236,14 @@ func ValidateRequest(validate *validator.Validate, method string, resource any)
 				switch tag {
 				case "api_version": // custom tag
 					message = fmt.Sprintf("Unrecognized API version '%s'", fieldErr.Value())
+				case "k8s_label_value": // custom tag
+					message += " (must be at most 63 alphanumerics, hyphens, underscores or periods, and begin and end with an alphanumeric)"
+				case "k8s_qualified_name": // custom tag
+					message += " (must be an optional DNS subdomain prefix and '/', followed by at most 63 alphanumerics, hyphens, underscores or periods that begin and end with an alphanumeric)"
 				case "pem_certificates": // custom tag
 					message += " (must provide PEM encoded certificates)"
+				case "resource_group_name": // custom tag
+					message += " (must be 1-90 characters of alphanumerics, underscores, parentheses, hyphens or periods, and not end with a period)"
 				case "required", "required_for_put": // custom tag
 					message = fmt.Sprintf("Missing required field '%s'", fieldErr.Field())
 				case "cidrv4":
@@ -206,6 +300,148 @@ func ValidateRequest(validate *validator.Validate, method string, resource any)
 	return errorDetails
 }
 
+// Azure resource tag limits.
+// See https://learn.microsoft.com/azure/azure-resource-manager/management/tag-resources#limitations
+const (
+	maxTagCount       = 50
+	maxTagKeyLength   = 512
+	maxTagValueLength = 256
+
+	// invalidTagKeyChars are characters Azure does not allow in tag keys.
+	invalidTagKeyChars = `<>%&\?/`
+)
+
+// reservedTagKeyPrefixes are tag key prefixes reserved by Azure.
+// The comparison is case-insensitive.
+var reservedTagKeyPrefixes = []string{"microsoft", "azure", "windows"}
+
+// ValidateTags checks a resource's tags against the limits Azure imposes,
+// so violations are reported up front rather than surfacing later as an
+// opaque Cluster Service or Cosmos DB error.
+func ValidateTags(tags map[string]string) []arm.CloudErrorBody {
+	var errorDetails []arm.CloudErrorBody
+
+	if len(tags) > maxTagCount {
+		errorDetails = append(errorDetails, arm.CloudErrorBody{
+			Code:    arm.CloudErrorCodeInvalidRequestContent,
+			Message: fmt.Sprintf("Too many tags (%d), at most %d are allowed", len(tags), maxTagCount),
+			Target:  "tags",
+		})
+	}
+
+	// Sort the keys so errors are reported in a stable order.
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		value := tags[key]
+		target := fmt.Sprintf("tags[%s]", key)
+
+		var message string
+		switch {
+		case key == "":
+			message = "Tag keys must not be empty"
+		case len(key) > maxTagKeyLength:
+			message = fmt.Sprintf("Tag key '%s' is too long (must be at most %d characters)", key, maxTagKeyLength)
+		case strings.ContainsAny(key, invalidTagKeyChars):
+			message = fmt.Sprintf("Tag key '%s' contains an invalid character (must not contain any of: %s)", key, invalidTagKeyChars)
+		case slices.ContainsFunc(reservedTagKeyPrefixes, func(prefix string) bool {
+			return len(key) >= len(prefix) && strings.EqualFold(key[:len(prefix)], prefix)
+		}):
+			message = fmt.Sprintf("Tag key '%s' uses a reserved prefix (must not begin with any of: %s)", key, strings.Join(reservedTagKeyPrefixes, ", "))
+		case len(value) > maxTagValueLength:
+			message = fmt.Sprintf("Value of tag '%s' is too long (must be at most %d characters)", key, maxTagValueLength)
+		default:
+			continue
+		}
+
+		errorDetails = append(errorDetails, arm.CloudErrorBody{
+			Code:    arm.CloudErrorCodeInvalidRequestContent,
+			Message: message,
+			Target:  target,
+		})
+	}
+
+	return errorDetails
+}
+
+// ValidateProxy checks a cluster's proxy configuration beyond what the
+// struct tags can express: proxy URLs must have a host, and each entry of
+// the comma-separated noProxy list must be a domain name, IP address or
+// CIDR range. Malformed values are otherwise only noticed once they break
+// the cluster's networking after provisioning. Empty noProxy entries are
+// ignored, and credentials in proxy URLs are redacted from error messages.
+func ValidateProxy(proxy *ProxyProfile) []arm.CloudErrorBody {
+	var errorDetails []arm.CloudErrorBody
+
+	for _, field := range []struct {
+		name    string
+		value   string
+		schemes []string
+	}{
+		// The "startswith" struct tag already checks the httpProxy scheme.
+		{"httpProxy", proxy.HTTPProxy, nil},
+		{"httpsProxy", proxy.HTTPSProxy, []string{"http", "https"}},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		// Values that are not URLs at all are reported by the "url" struct tag.
+		proxyURL, err := url.Parse(field.value)
+		if err != nil || proxyURL.Scheme == "" {
+			continue
+		}
+
+		var message string
+		switch {
+		case field.schemes != nil && !slices.Contains(field.schemes, strings.ToLower(proxyURL.Scheme)):
+			message = fmt.Sprintf("Invalid value '%s' for field '%s' (scheme must be one of: %s)", redactURLCredentials(field.value), field.name, strings.Join(field.schemes, ", "))
+		case proxyURL.Hostname() == "":
+			message = fmt.Sprintf("Invalid value '%s' for field '%s' (must include a host)", redactURLCredentials(field.value), field.name)
+		default:
+			continue
+		}
+
+		errorDetails = append(errorDetails, arm.CloudErrorBody{
+			Code:    arm.CloudErrorCodeInvalidRequestContent,
+			Message: message,
+			Target:  "properties.spec.proxy." + field.name,
+		})
+	}
+
+	if proxy.NoProxy != "" {
+		for i, entry := range strings.Split(proxy.NoProxy, ",") {
+			entry = strings.TrimSpace(entry)
+			// Empty entries, such as from a trailing comma, are ignored.
+			if entry == "" || isNoProxyEntry(entry) {
+				continue
+			}
+			errorDetails = append(errorDetails, arm.CloudErrorBody{
+				Code:    arm.CloudErrorCodeInvalidRequestContent,
+				Message: fmt.Sprintf("Invalid noProxy entry '%s' (must be a domain name, IP address or CIDR range)", entry),
+				Target:  fmt.Sprintf("properties.spec.proxy.noProxy[%d]", i),
+			})
+		}
+	}
+
+	return errorDetails
+}
+
+// isNoProxyEntry returns true if entry is a valid element of a noProxy
+// list: "*", an IP address, a CIDR range, or a domain name optionally
+// preceded by a period to match its subdomains.
+func isNoProxyEntry(entry string) bool {
+	if entry == "*" {
+		return true
+	}
+	if _, err := netip.ParseAddr(entry); err == nil {
+		return true
+	}
+	if _, err := netip.ParsePrefix(entry); err == nil {
+		return true
+	}
+	domain := strings.ToLower(strings.TrimPrefix(entry, "."))
+	return len(domain) > 0 && len(domain) <= k8sDNSSubdomainMaxLength && k8sDNSSubdomainRegexp.MatchString(domain)
+}
+
 // ValidateSubscription validates a subscription request payload.
 func ValidateSubscription(subscription *arm.Subscription) *arm.CloudError {
 	cloudError := arm.NewCloudError(
@@ -231,3 +467,33 @@ func ValidateSubscription(subscription *arm.Subscription) *arm.CloudError {
 
 	return cloudError
 }
+
+// KnownSubscriptionFeatures is the allowlist of subscription feature names
+// the resource provider recognizes. Add a feature here when code starts to
+// depend on it. Registered features missing from this list are reported as
+// unknown when ARM notifies the resource provider of subscription changes.
+var KnownSubscriptionFeatures = []string{}
+
+// UnknownSubscriptionFeatures returns the names of features registered for
+// a subscription that are not in the list of known features, sorted and
+// without duplicates. Feature names are compared case-insensitively since
+// ARM does not guarantee their casing.
+func UnknownSubscriptionFeatures(subscription *arm.Subscription, knownFeatures []string) []string {
+	if subscription == nil || subscription.Properties == nil || subscription.Properties.RegisteredFeatures == nil {
+		return nil
+	}
+
+	known := make(map[string]bool, len(knownFeatures))
+	for _, name := range knownFeatures {
+		known[strings.ToLower(name)] = true
+	}
+
+	unknown := make(map[string]bool)
+	for _, feature := range *subscription.Properties.RegisteredFeatures {
+		if feature.Name != nil && !known[strings.ToLower(*feature.Name)] {
+			unknown[*feature.Name] = true
+		}
+	}
+
+	return slices.Sorted(maps.Keys(unknown))
+}
@@ -285,7 +285,7 @@ func (vv *validateVisibility) checkFlags(flags VisibilityFlags, namespace, field
 		vv.errs = append(vv.errs,
 			arm.CloudErrorBody{
 				Code:    arm.CloudErrorCodeInvalidRequestContent,
-				Message: fmt.Sprintf("Field '%s' cannot be updated", fieldname),
+				Message: fmt.Sprintf("Cannot update field '%s': field is immutable", fieldname),
 				Target:  join(namespace, fieldname),
 			})
 	}
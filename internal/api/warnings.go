@@ -0,0 +1,67 @@
+package api
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+const (
+	// minRecommendedNodeCount is the node count below which a cluster
+	// network configuration is considered likely to be unintended.
+	minRecommendedNodeCount = 16
+
+	// azureSubnetReservedAddresses is the number of addresses Azure
+	// reserves in every subnet.
+	azureSubnetReservedAddresses = 5
+)
+
+// ValidationWarning describes a condition in a request that is valid but
+// likely unintended. Unlike validation errors, warnings never cause the
+// request to be rejected.
+type ValidationWarning struct {
+	Message string `json:"message"`
+	Target  string `json:"target,omitempty"`
+}
+
+// String returns the warning in a form suitable for a response header.
+func (w ValidationWarning) String() string {
+	if w.Target == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("%s: %s", w.Target, w.Message)
+}
+
+// ValidateClusterWarnings returns warnings for a cluster that has already
+// passed static validation.
+func ValidateClusterWarnings(cluster *HCPOpenShiftCluster) []ValidationWarning {
+	var warnings []ValidationWarning
+
+	network := &cluster.Properties.Spec.Network
+
+	// The host prefix is the size of the pod subnet assigned to each node,
+	// so together with the pod CIDR it caps the number of nodes.
+	if podPrefix, err := netip.ParsePrefix(network.PodCIDR); err == nil {
+		subnetBits := int(network.HostPrefix) - podPrefix.Bits()
+		if network.HostPrefix > 0 && subnetBits >= 0 && subnetBits < 31 && 1<<subnetBits < minRecommendedNodeCount {
+			warnings = append(warnings, ValidationWarning{
+				Message: fmt.Sprintf("Host prefix /%d with pod CIDR '%s' allows at most %d nodes", network.HostPrefix, network.PodCIDR, 1<<subnetBits),
+				Target:  "properties.spec.network.hostPrefix",
+			})
+		}
+	}
+
+	if machinePrefix, err := netip.ParsePrefix(network.MachineCIDR); err == nil {
+		addressBits := machinePrefix.Addr().BitLen() - machinePrefix.Bits()
+		if addressBits < 31 && 1<<addressBits-azureSubnetReservedAddresses < minRecommendedNodeCount {
+			warnings = append(warnings, ValidationWarning{
+				Message: fmt.Sprintf("Machine CIDR '%s' allows at most %d nodes", network.MachineCIDR, max(1<<addressBits-azureSubnetReservedAddresses, 0)),
+				Target:  "properties.spec.network.machineCidr",
+			})
+		}
+	}
+
+	return warnings
+}
@@ -0,0 +1,73 @@
+package api
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateClusterWarnings(t *testing.T) {
+	tests := []struct {
+		name           string
+		podCIDR        string
+		machineCIDR    string
+		hostPrefix     int32
+		expectWarnings []ValidationWarning
+	}{
+		{
+			name:        "Default network",
+			podCIDR:     "10.128.0.0/14",
+			machineCIDR: "10.0.0.0/16",
+			hostPrefix:  23,
+		},
+		{
+			name:        "Host prefix allows few nodes",
+			podCIDR:     "10.128.0.0/20",
+			machineCIDR: "10.0.0.0/16",
+			hostPrefix:  23,
+			expectWarnings: []ValidationWarning{
+				{
+					Message: "Host prefix /23 with pod CIDR '10.128.0.0/20' allows at most 8 nodes",
+					Target:  "properties.spec.network.hostPrefix",
+				},
+			},
+		},
+		{
+			name:        "Machine CIDR allows few nodes",
+			podCIDR:     "10.128.0.0/14",
+			machineCIDR: "10.0.0.0/28",
+			hostPrefix:  23,
+			expectWarnings: []ValidationWarning{
+				{
+					Message: "Machine CIDR '10.0.0.0/28' allows at most 11 nodes",
+					Target:  "properties.spec.network.machineCidr",
+				},
+			},
+		},
+		{
+			name:        "Host prefix shorter than pod CIDR is left to other checks",
+			podCIDR:     "10.128.0.0/24",
+			machineCIDR: "10.0.0.0/16",
+			hostPrefix:  23,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := NewDefaultHCPOpenShiftCluster()
+			cluster.Properties.Spec.Network.PodCIDR = tt.podCIDR
+			cluster.Properties.Spec.Network.MachineCIDR = tt.machineCIDR
+			cluster.Properties.Spec.Network.HostPrefix = tt.hostPrefix
+
+			warnings := ValidateClusterWarnings(cluster)
+
+			diff := cmp.Diff(tt.expectWarnings, warnings)
+			if diff != "" {
+				t.Fatalf("Expected warning mismatch:\n%s", diff)
+			}
+		})
+	}
+}
@@ -7,9 +7,15 @@ import (
 	"context"
 	"encoding/json"
 	"iter"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/google/uuid"
+
 	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/ocm"
 )
 
 var _ DBClient = &Cache{}
@@ -23,8 +29,9 @@ type Cache struct {
 }
 
 type cacheIterator struct {
-	docs []any
-	err  error
+	docs              []any
+	continuationToken string
+	err               error
 }
 
 func (iter cacheIterator) Items(ctx context.Context) iter.Seq[[]byte] {
@@ -47,13 +54,20 @@ func (iter cacheIterator) Items(ctx context.Context) iter.Seq[[]byte] {
 }
 
 func (iter cacheIterator) GetContinuationToken() string {
-	return ""
+	return iter.continuationToken
 }
 
 func (iter cacheIterator) GetError() error {
 	return iter.err
 }
 
+// newCacheETag returns a unique entity tag for a cached document, so that
+// a cached document changes its entity tag on every write as a Cosmos DB
+// item does.
+func newCacheETag() azcore.ETag {
+	return azcore.ETag(uuid.NewString())
+}
+
 // NewCache initializes a new Cache to allow for simple tests without needing a real CosmosDB. For production, use
 // NewCosmosDBConfig instead.
 func NewCache() DBClient {
@@ -83,10 +97,32 @@ func (c *Cache) GetResourceDoc(ctx context.Context, resourceID *arm.ResourceID)
 	return nil, ErrNotFound
 }
 
+func (c *Cache) GetResourceDocByInternalID(ctx context.Context, internalID ocm.InternalID) (*ResourceDocument, error) {
+	for _, doc := range c.resource {
+		if doc.InternalID == internalID {
+			return doc, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+func (c *Cache) GetResourceDocs(ctx context.Context, internalIDs []ocm.InternalID) (map[ocm.InternalID]*ResourceDocument, error) {
+	docs := make(map[ocm.InternalID]*ResourceDocument, len(internalIDs))
+	for _, doc := range c.resource {
+		if slices.Contains(internalIDs, doc.InternalID) {
+			docs[doc.InternalID] = doc
+		}
+	}
+
+	return docs, nil
+}
+
 func (c *Cache) CreateResourceDoc(ctx context.Context, doc *ResourceDocument) error {
 	// Make sure lookup keys are lowercase.
 	key := strings.ToLower(doc.Key.String())
 
+	doc.ETag = newCacheETag()
 	c.resource[key] = doc
 	return nil
 }
@@ -96,7 +132,11 @@ func (c *Cache) UpdateResourceDoc(ctx context.Context, resourceID *arm.ResourceI
 	key := strings.ToLower(resourceID.String())
 
 	if doc, ok := c.resource[key]; ok {
-		return callback(doc), nil
+		if !callback(doc) {
+			return false, nil
+		}
+		doc.ETag = newCacheETag()
+		return true, nil
 	}
 
 	return false, ErrNotFound
@@ -110,18 +150,39 @@ func (c *Cache) DeleteResourceDoc(ctx context.Context, resourceID *arm.ResourceI
 	return nil
 }
 
-func (c *Cache) ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, maxItems int32, continuationToken *string) DBClientIterator {
+func (c *Cache) ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, filter *ResourceDocumentFilter, maxItems int32, continuationToken *string) DBClientIterator {
 	var iterator cacheIterator
 
 	// Make sure key prefix is lowercase.
 	prefixString := strings.ToLower(prefix.String() + "/")
 
+	var keys []string
 	for key, doc := range c.resource {
-		if strings.HasPrefix(key, prefixString) {
-			iterator.docs = append(iterator.docs, doc)
+		if strings.HasPrefix(key, prefixString) && filter.Matches(doc) {
+			keys = append(keys, key)
 		}
 	}
 
+	// Sort the keys so the continuation token, which is
+	// just an offset into the sorted keys, is meaningful.
+	slices.Sort(keys)
+
+	var offset int
+	if continuationToken != nil {
+		offset, _ = strconv.Atoi(*continuationToken)
+		offset = min(max(offset, 0), len(keys))
+	}
+	keys = keys[offset:]
+
+	if maxItems > 0 && len(keys) > int(maxItems) {
+		keys = keys[:maxItems]
+		iterator.continuationToken = strconv.Itoa(offset + int(maxItems))
+	}
+
+	for _, key := range keys {
+		iterator.docs = append(iterator.docs, c.resource[key])
+	}
+
 	return iterator
 }
 
@@ -171,6 +232,16 @@ func (c *Cache) ListAllOperationDocs(ctx context.Context) DBClientIterator {
 	return iterator
 }
 
+func (c *Cache) ListOperationDocs(ctx context.Context, filter *OperationDocumentFilter) DBClientIterator {
+	var iterator cacheIterator
+	for _, doc := range c.operation {
+		if filter.Matches(doc) {
+			iterator.docs = append(iterator.docs, doc)
+		}
+	}
+	return iterator
+}
+
 func (c *Cache) GetSubscriptionDoc(ctx context.Context, subscriptionID string) (*SubscriptionDocument, error) {
 	// Make sure lookup keys are lowercase.
 	key := strings.ToLower(subscriptionID)
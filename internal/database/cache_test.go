@@ -0,0 +1,236 @@
+package database
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestCacheGetResourceDocByInternalID(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache()
+
+	for _, name := range []string{"cluster1", "cluster2"} {
+		resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := NewResourceDocument(resourceID)
+		doc.InternalID, err = ocm.NewInternalID(ocm.GenerateClusterHREF(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cache.CreateResourceDoc(ctx, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	internalID, err := ocm.NewInternalID(ocm.GenerateClusterHREF("cluster2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := cache.GetResourceDocByInternalID(ctx, internalID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Key.Name != "cluster2" {
+		t.Errorf("expected resource document for cluster2, got %s", doc.Key)
+	}
+
+	internalID, err = ocm.NewInternalID(ocm.GenerateClusterHREF("cluster3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cache.GetResourceDocByInternalID(ctx, internalID)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCacheGetResourceDocs(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache()
+
+	var internalIDs []ocm.InternalID
+	for _, name := range []string{"cluster1", "cluster2", "cluster3"} {
+		resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := NewResourceDocument(resourceID)
+		doc.InternalID, err = ocm.NewInternalID(ocm.GenerateClusterHREF(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cache.CreateResourceDoc(ctx, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		internalIDs = append(internalIDs, doc.InternalID)
+	}
+
+	missingID, err := ocm.NewInternalID(ocm.GenerateClusterHREF("cluster4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := cache.GetResourceDocs(ctx, []ocm.InternalID{internalIDs[0], internalIDs[2], missingID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 resource documents, got %d", len(docs))
+	}
+	if doc, ok := docs[internalIDs[2]]; !ok || doc.Key.Name != "cluster3" {
+		t.Errorf("expected resource document for cluster3, got %v", doc)
+	}
+	if _, ok := docs[missingID]; ok {
+		t.Errorf("expected no resource document for cluster4")
+	}
+}
+
+func TestCacheResourceDocETag(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache()
+
+	resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/cluster1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := NewResourceDocument(resourceID)
+	err = cache.CreateResourceDoc(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := doc.ETag
+	if etag == "" {
+		t.Fatal("expected an entity tag after create")
+	}
+
+	_, err = cache.UpdateResourceDoc(ctx, resourceID, func(updateDoc *ResourceDocument) bool {
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ETag != etag {
+		t.Errorf("expected entity tag %s to survive a declined update, got %s", etag, doc.ETag)
+	}
+
+	_, err = cache.UpdateResourceDoc(ctx, resourceID, func(updateDoc *ResourceDocument) bool {
+		updateDoc.Tags = map[string]string{"a": "1"}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.ETag == etag {
+		t.Errorf("expected a new entity tag after update, got %s", doc.ETag)
+	}
+}
+
+func TestCacheListOperationDocs(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache()
+
+	var resourceIDs []*arm.ResourceID
+	for _, name := range []string{"cluster1", "cluster2"} {
+		resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resourceIDs = append(resourceIDs, resourceID)
+	}
+
+	internalID, err := ocm.NewInternalID("/api/clusters_mgmt/v1/clusters/placeholder")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []struct {
+		resourceID *arm.ResourceID
+		status     arm.ProvisioningState
+	}{
+		{resourceIDs[0], arm.ProvisioningStateSucceeded},
+		{resourceIDs[0], arm.ProvisioningStateUpdating},
+		{resourceIDs[1], arm.ProvisioningStateFailed},
+		{resourceIDs[1], arm.ProvisioningStateProvisioning},
+	} {
+		doc := NewOperationDocument(OperationRequestUpdate, op.resourceID, internalID)
+		doc.Status = op.status
+		err := cache.CreateOperationDoc(ctx, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		filter        *OperationDocumentFilter
+		expectedCount int
+	}{
+		{
+			name:          "Nil filter matches all",
+			filter:        nil,
+			expectedCount: 4,
+		},
+		{
+			name: "Single status",
+			filter: &OperationDocumentFilter{
+				Statuses: []arm.ProvisioningState{arm.ProvisioningStateSucceeded},
+			},
+			expectedCount: 1,
+		},
+		{
+			name: "Multiple statuses",
+			filter: &OperationDocumentFilter{
+				Statuses: []arm.ProvisioningState{arm.ProvisioningStateUpdating, arm.ProvisioningStateProvisioning},
+			},
+			expectedCount: 2,
+		},
+		{
+			name: "Status and resource",
+			filter: &OperationDocumentFilter{
+				Statuses:   []arm.ProvisioningState{arm.ProvisioningStateUpdating, arm.ProvisioningStateProvisioning},
+				ExternalID: resourceIDs[1],
+			},
+			expectedCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iterator := cache.ListOperationDocs(ctx, tt.filter)
+
+			var count int
+			for item := range iterator.Items(ctx) {
+				var doc OperationDocument
+				err := json.Unmarshal(item, &doc)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tt.filter.Matches(&doc) {
+					t.Errorf("unexpected operation with status %s on %s", doc.Status, doc.ExternalID)
+				}
+				count++
+			}
+			if err := iterator.GetError(); err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.expectedCount {
+				t.Errorf("expected %d operations, got %d", tt.expectedCount, count)
+			}
+		})
+	}
+}
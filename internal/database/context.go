@@ -0,0 +1,28 @@
+package database
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"log/slog"
+)
+
+type contextKey int
+
+const contextKeyLogger contextKey = iota
+
+// ContextWithLogger returns a copy of ctx carrying a logger for the
+// database client to log through.
+func ContextWithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKeyLogger, logger)
+}
+
+// loggerFromContext returns the logger carried by ctx, or the default
+// logger if there is none.
+func loggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKeyLogger).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
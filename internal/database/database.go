@@ -9,13 +9,19 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/ocm"
 )
 
 const (
@@ -39,6 +45,10 @@ const (
 	//
 	//     [1] https://github.com/Azure/azure-sdk-for-go/issues/18578
 	operationsPartitionKey = "workaround"
+
+	// DefaultOperationTimeToLive is how long an operation document is
+	// kept once the operation reaches a terminal state.
+	DefaultOperationTimeToLive = 7 * 24 * time.Hour
 )
 
 var ErrNotFound = errors.New("not found")
@@ -48,6 +58,28 @@ func isResponseError(err error, statusCode int) bool {
 	return errors.As(err, &responseError) && responseError.StatusCode == statusCode
 }
 
+// IsThrottledError returns true if err indicates Cosmos DB rejected a request
+// because the request rate is too high, along with how long Cosmos DB advises
+// waiting before retrying. The duration is zero if Cosmos DB gave no advice.
+func IsThrottledError(err error) (time.Duration, bool) {
+	var responseError *azcore.ResponseError
+	if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	var retryAfter time.Duration
+	if responseError.RawResponse != nil {
+		header := responseError.RawResponse.Header
+		if ms, err := strconv.Atoi(header.Get("x-ms-retry-after-ms")); err == nil && ms > 0 {
+			retryAfter = time.Duration(ms) * time.Millisecond
+		} else if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return retryAfter, true
+}
+
 type DBClientIterator interface {
 	Items(ctx context.Context) iter.Seq[[]byte]
 	GetContinuationToken() string
@@ -66,18 +98,31 @@ type DBClient interface {
 	// GetResourceDoc retrieves a ResourceDocument from the database given its resourceID.
 	// ErrNotFound is returned if an associated ResourceDocument cannot be found.
 	GetResourceDoc(ctx context.Context, resourceID *arm.ResourceID) (*ResourceDocument, error)
+	// GetResourceDocByInternalID retrieves a ResourceDocument from the database given the
+	// Cluster Service ID of its resource. ErrNotFound is returned if no ResourceDocument
+	// has the internal ID.
+	GetResourceDocByInternalID(ctx context.Context, internalID ocm.InternalID) (*ResourceDocument, error)
+	// GetResourceDocs retrieves the ResourceDocuments with the given Cluster Service IDs
+	// in a single query, keyed by internal ID. Internal IDs with no ResourceDocument are
+	// absent from the returned map.
+	GetResourceDocs(ctx context.Context, internalIDs []ocm.InternalID) (map[ocm.InternalID]*ResourceDocument, error)
 	CreateResourceDoc(ctx context.Context, doc *ResourceDocument) error
 	UpdateResourceDoc(ctx context.Context, resourceID *arm.ResourceID, callback func(*ResourceDocument) bool) (bool, error)
 	// DeleteResourceDoc deletes a ResourceDocument from the database given the resourceID
 	// of a Microsoft.RedHatOpenShift/HcpOpenShiftClusters resource or NodePools child resource.
 	DeleteResourceDoc(ctx context.Context, resourceID *arm.ResourceID) error
-	ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, maxItems int32, continuationToken *string) DBClientIterator
+	// ListResourceDocs returns an iterator over ResourceDocuments whose resource ID starts
+	// with the given prefix. An optional filter further narrows the documents returned.
+	ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, filter *ResourceDocumentFilter, maxItems int32, continuationToken *string) DBClientIterator
 
 	GetOperationDoc(ctx context.Context, operationID string) (*OperationDocument, error)
 	CreateOperationDoc(ctx context.Context, doc *OperationDocument) error
 	UpdateOperationDoc(ctx context.Context, operationID string, callback func(*OperationDocument) bool) (bool, error)
 	DeleteOperationDoc(ctx context.Context, operationID string) error
 	ListAllOperationDocs(ctx context.Context) DBClientIterator
+	// ListOperationDocs returns an iterator over OperationDocuments matching
+	// the given filter. A nil filter matches every document.
+	ListOperationDocs(ctx context.Context, filter *OperationDocumentFilter) DBClientIterator
 
 	// GetSubscriptionDoc retrieves a SubscriptionDocument from the database given the subscriptionID.
 	// ErrNotFound is returned if an associated SubscriptionDocument cannot be found.
@@ -95,10 +140,15 @@ type CosmosDBClient struct {
 	operations    *azcosmos.ContainerClient
 	subscriptions *azcosmos.ContainerClient
 	lockClient    *LockClient
+
+	operationTimeToLive time.Duration
+	transactionAttempts *prometheus.CounterVec
 }
 
-// NewCosmosDBClient instantiates a Cosmos DatabaseClient targeting Frontends async DB
-func NewCosmosDBClient(ctx context.Context, database *azcosmos.DatabaseClient) (DBClient, error) {
+// NewCosmosDBClient instantiates a Cosmos DatabaseClient targeting Frontends async DB.
+// Operation documents expire operationTimeToLive after reaching a terminal state.
+// A non-positive operationTimeToLive defers to the Operations container's default.
+func NewCosmosDBClient(ctx context.Context, database *azcosmos.DatabaseClient, operationTimeToLive time.Duration, registerer prometheus.Registerer) (DBClient, error) {
 	// NewContainer only fails if the container ID argument is
 	// empty, so we can safely disregard the error return value.
 	resources, _ := database.NewContainer(resourcesContainer)
@@ -111,12 +161,20 @@ func NewCosmosDBClient(ctx context.Context, database *azcosmos.DatabaseClient) (
 		return nil, err
 	}
 
+	transactionAttempts, err := newTransactionAttemptsCounter(registerer)
+	if err != nil {
+		return nil, err
+	}
+
 	return &CosmosDBClient{
 		database:      database,
 		resources:     resources,
 		operations:    operations,
 		subscriptions: subscriptions,
 		lockClient:    lockClient,
+
+		operationTimeToLive: operationTimeToLive,
+		transactionAttempts: transactionAttempts,
 	}, nil
 }
 
@@ -181,6 +239,95 @@ func (d *CosmosDBClient) GetResourceDoc(ctx context.Context, resourceID *arm.Res
 	return nil, fmt.Errorf("failed to read Resources container item for '%s': %w", resourceID, ErrNotFound)
 }
 
+// GetResourceDocByInternalID retrieves a resource document from the "resources" DB using
+// the Cluster Service ID of the resource
+func (d *CosmosDBClient) GetResourceDocByInternalID(ctx context.Context, internalID ocm.InternalID) (*ResourceDocument, error) {
+	// The internal ID does not tell us the subscription,
+	// so this has to be a cross-partition query.
+	pk := azcosmos.NewPartitionKey()
+
+	// InternalID values are always stored in lowercase so a plain
+	// equality comparison, which can be served from the index, is
+	// sufficient.
+	query := "SELECT * FROM c WHERE c.internalId = @internalId"
+	opt := azcosmos.QueryOptions{
+		PageSizeHint:    1,
+		QueryParameters: []azcosmos.QueryParameter{{Name: "@internalId", Value: internalID.String()}},
+	}
+
+	queryPager := d.resources.NewQueryItemsPager(query, pk, &opt)
+
+	var doc *ResourceDocument
+	for queryPager.More() {
+		queryResponse, err := queryPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page while querying Resources container for '%s': %w", internalID.String(), err)
+		}
+
+		for _, item := range queryResponse.Items {
+			err = json.Unmarshal(item, &doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal Resources container item for '%s': %w", internalID.String(), err)
+			}
+		}
+	}
+	if doc != nil {
+		return doc, nil
+	}
+	return nil, fmt.Errorf("failed to read Resources container item for '%s': %w", internalID.String(), ErrNotFound)
+}
+
+// GetResourceDocs retrieves resource documents from the "resources" DB using
+// the Cluster Service IDs of the resources, in a single cross-partition query
+func (d *CosmosDBClient) GetResourceDocs(ctx context.Context, internalIDs []ocm.InternalID) (map[ocm.InternalID]*ResourceDocument, error) {
+	docs := make(map[ocm.InternalID]*ResourceDocument, len(internalIDs))
+
+	// The internal IDs do not tell us the subscriptions,
+	// so this has to be a cross-partition query.
+	pk := azcosmos.NewPartitionKey()
+
+	var opt azcosmos.QueryOptions
+	var params []string
+	for _, internalID := range internalIDs {
+		value := internalID.String()
+		if slices.ContainsFunc(opt.QueryParameters, func(p azcosmos.QueryParameter) bool { return p.Value == value }) {
+			continue
+		}
+		param := fmt.Sprintf("@internalId%d", len(params))
+		params = append(params, param)
+		opt.QueryParameters = append(opt.QueryParameters, azcosmos.QueryParameter{Name: param, Value: value})
+	}
+
+	if len(params) == 0 {
+		return docs, nil
+	}
+
+	// InternalID values are always stored in lowercase so a plain
+	// membership test, which can be served from the index, is
+	// sufficient.
+	query := fmt.Sprintf("SELECT * FROM c WHERE c.internalId IN (%s)", strings.Join(params, ", "))
+
+	queryPager := d.resources.NewQueryItemsPager(query, pk, &opt)
+
+	for queryPager.More() {
+		queryResponse, err := queryPager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page while querying Resources container for %d internal IDs: %w", len(params), err)
+		}
+
+		for _, item := range queryResponse.Items {
+			var doc *ResourceDocument
+			err = json.Unmarshal(item, &doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal Resources container item: %w", err)
+			}
+			docs[doc.InternalID] = doc
+		}
+	}
+
+	return docs, nil
+}
+
 // CreateResourceDoc creates a resource document in the "resources" DB during resource creation
 func (d *CosmosDBClient) CreateResourceDoc(ctx context.Context, doc *ResourceDocument) error {
 	// Make sure partition key is lowercase.
@@ -191,11 +338,14 @@ func (d *CosmosDBClient) CreateResourceDoc(ctx context.Context, doc *ResourceDoc
 		return fmt.Errorf("failed to marshal Resources container item for '%s': %w", doc.Key, err)
 	}
 
-	_, err = d.resources.CreateItem(ctx, azcosmos.NewPartitionKeyString(doc.PartitionKey), data, nil)
+	response, err := d.resources.CreateItem(ctx, azcosmos.NewPartitionKeyString(doc.PartitionKey), data, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create Resources container item for '%s': %w", doc.Key, err)
 	}
 
+	// Callers report the entity tag of the new document.
+	doc.ETag = response.ETag
+
 	return nil
 }
 
@@ -236,14 +386,17 @@ func (d *CosmosDBClient) UpdateResourceDoc(ctx context.Context, resourceID *arm.
 		options.IfMatchEtag = &doc.ETag
 		_, err = d.resources.ReplaceItem(ctx, pk, doc.ID, data, options)
 		if err == nil {
+			d.recordTransactionAttempt(ctx, resourcesContainer, try, transactionOutcomeSuccess)
 			return true, nil
 		}
 
 		var responseError *azcore.ResponseError
 		err = fmt.Errorf("failed to replace Resources container item for '%s': %w", resourceID, err)
 		if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusPreconditionFailed {
+			d.recordTransactionAttempt(ctx, resourcesContainer, try, transactionOutcomeError)
 			return false, err
 		}
+		d.recordTransactionAttempt(ctx, resourcesContainer, try, transactionOutcomeConflict)
 	}
 
 	return false, err
@@ -269,11 +422,12 @@ func (d *CosmosDBClient) DeleteResourceDoc(ctx context.Context, resourceID *arm.
 	return nil
 }
 
-// ListResourceDocs searches for resource documents that match the given resource ID prefix.
-// maxItems can limit the number of items returned at once. A negative value will cause the
-// returned iterator to yield all matching items. A positive value will cause the returned
-// iterator to include a continuation token if additional items are available.
-func (d *CosmosDBClient) ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, maxItems int32, continuationToken *string) DBClientIterator {
+// ListResourceDocs searches for resource documents that match the given resource ID prefix
+// and, if non-nil, the given filter. maxItems can limit the number of items returned at once.
+// A negative value will cause the returned iterator to yield all matching items. A positive
+// value will cause the returned iterator to include a continuation token if additional items
+// are available.
+func (d *CosmosDBClient) ListResourceDocs(ctx context.Context, prefix *arm.ResourceID, filter *ResourceDocumentFilter, maxItems int32, continuationToken *string) DBClientIterator {
 	// Make sure partition key is lowercase.
 	pk := azcosmos.NewPartitionKeyString(strings.ToLower(prefix.SubscriptionID))
 
@@ -295,6 +449,28 @@ func (d *CosmosDBClient) ListResourceDocs(ctx context.Context, prefix *arm.Resou
 		},
 	}
 
+	if filter != nil {
+		if filter.ProvisioningState != "" {
+			query += " AND STRINGEQUALS(c.provisioningState, @provisioningState, true)"
+			opt.QueryParameters = append(opt.QueryParameters, azcosmos.QueryParameter{
+				Name:  "@provisioningState",
+				Value: string(filter.ProvisioningState),
+			})
+		}
+
+		// Sort tag keys so the query text, and therefore
+		// any continuation token derived from it, is stable.
+		tagKeys := slices.Sorted(maps.Keys(filter.Tags))
+		for i, key := range tagKeys {
+			keyParam := fmt.Sprintf("@tagKey%d", i)
+			valueParam := fmt.Sprintf("@tagValue%d", i)
+			query += fmt.Sprintf(" AND c.tags[%s] = %s", keyParam, valueParam)
+			opt.QueryParameters = append(opt.QueryParameters,
+				azcosmos.QueryParameter{Name: keyParam, Value: key},
+				azcosmos.QueryParameter{Name: valueParam, Value: filter.Tags[key]})
+		}
+	}
+
 	pager := d.resources.NewQueryItemsPager(query, pk, &opt)
 
 	if maxItems > 0 {
@@ -334,6 +510,8 @@ func (d *CosmosDBClient) GetOperationDoc(ctx context.Context, operationID string
 func (d *CosmosDBClient) CreateOperationDoc(ctx context.Context, doc *OperationDocument) error {
 	pk := azcosmos.NewPartitionKeyString(operationsPartitionKey)
 
+	doc.setTimeToLive(d.operationTimeToLive)
+
 	data, err := json.Marshal(doc)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Operations container item for '%s': %w", doc.ID, err)
@@ -375,6 +553,9 @@ func (d *CosmosDBClient) UpdateOperationDoc(ctx context.Context, operationID str
 			return false, nil
 		}
 
+		// Start the expiry clock once the operation is finished.
+		doc.setTimeToLive(d.operationTimeToLive)
+
 		data, err = json.Marshal(doc)
 		if err != nil {
 			return false, fmt.Errorf("failed to marshal Operations container item for '%s': %w", operationID, err)
@@ -383,14 +564,17 @@ func (d *CosmosDBClient) UpdateOperationDoc(ctx context.Context, operationID str
 		options.IfMatchEtag = &doc.ETag
 		_, err = d.operations.ReplaceItem(ctx, pk, doc.ID, data, options)
 		if err == nil {
+			d.recordTransactionAttempt(ctx, operationsContainer, try, transactionOutcomeSuccess)
 			return true, nil
 		}
 
 		var responseError *azcore.ResponseError
 		err = fmt.Errorf("failed to replace Operations container item for '%s': %w", operationID, err)
 		if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusPreconditionFailed {
+			d.recordTransactionAttempt(ctx, operationsContainer, try, transactionOutcomeError)
 			return false, err
 		}
+		d.recordTransactionAttempt(ctx, operationsContainer, try, transactionOutcomeConflict)
 	}
 
 	return false, err
@@ -417,6 +601,43 @@ func (d *CosmosDBClient) ListAllOperationDocs(ctx context.Context) DBClientItera
 	return NewQueryItemsIterator(d.operations.NewQueryItemsPager("SELECT * FROM c", pk, nil))
 }
 
+// ListOperationDocs searches for operation documents that match the given
+// filter. The filter is evaluated by Cosmos DB so that only matching items
+// are read.
+func (d *CosmosDBClient) ListOperationDocs(ctx context.Context, filter *OperationDocumentFilter) DBClientIterator {
+	pk := azcosmos.NewPartitionKeyString(operationsPartitionKey)
+
+	query := "SELECT * FROM c"
+	opt := azcosmos.QueryOptions{}
+
+	if filter != nil {
+		var conditions []string
+
+		if len(filter.Statuses) > 0 {
+			var params []string
+			for i, status := range filter.Statuses {
+				param := fmt.Sprintf("@status%d", i)
+				params = append(params, param)
+				opt.QueryParameters = append(opt.QueryParameters,
+					azcosmos.QueryParameter{Name: param, Value: string(status)})
+			}
+			conditions = append(conditions, fmt.Sprintf("c.status IN (%s)", strings.Join(params, ", ")))
+		}
+
+		if filter.ExternalID != nil {
+			conditions = append(conditions, "STRINGEQUALS(c.externalId, @externalId, true)")
+			opt.QueryParameters = append(opt.QueryParameters,
+				azcosmos.QueryParameter{Name: "@externalId", Value: filter.ExternalID.String()})
+		}
+
+		if len(conditions) > 0 {
+			query += " WHERE " + strings.Join(conditions, " AND ")
+		}
+	}
+
+	return NewQueryItemsIterator(d.operations.NewQueryItemsPager(query, pk, &opt))
+}
+
 // GetSubscriptionDoc retreives a subscription document from async DB using the subscription ID
 func (d *CosmosDBClient) GetSubscriptionDoc(ctx context.Context, subscriptionID string) (*SubscriptionDocument, error) {
 	// Make sure lookup keys are lowercase.
@@ -498,14 +719,17 @@ func (d *CosmosDBClient) UpdateSubscriptionDoc(ctx context.Context, subscription
 		options.IfMatchEtag = &doc.ETag
 		_, err = d.subscriptions.ReplaceItem(ctx, pk, doc.ID, data, options)
 		if err == nil {
+			d.recordTransactionAttempt(ctx, subscriptionsContainer, try, transactionOutcomeSuccess)
 			return true, nil
 		}
 
 		var responseError *azcore.ResponseError
 		err = fmt.Errorf("failed to replace Subscriptions container item for '%s': %w", subscriptionID, err)
 		if !errors.As(err, &responseError) || responseError.StatusCode != http.StatusPreconditionFailed {
+			d.recordTransactionAttempt(ctx, subscriptionsContainer, try, transactionOutcomeError)
 			return false, err
 		}
+		d.recordTransactionAttempt(ctx, subscriptionsContainer, try, transactionOutcomeConflict)
 	}
 
 	return false, err
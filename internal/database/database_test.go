@@ -0,0 +1,353 @@
+package database
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azcosmos"
+
+	"github.com/Azure/ARO-HCP/internal/api/arm"
+	"github.com/Azure/ARO-HCP/internal/ocm"
+)
+
+func TestIsThrottledError(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                error
+		expectedRetryAfter time.Duration
+		expectedThrottled  bool
+	}{
+		{
+			name: "Retry delay in milliseconds",
+			err: &azcore.ResponseError{
+				StatusCode: http.StatusTooManyRequests,
+				RawResponse: &http.Response{
+					Header: http.Header{"X-Ms-Retry-After-Ms": []string{"1500"}},
+				},
+			},
+			expectedRetryAfter: 1500 * time.Millisecond,
+			expectedThrottled:  true,
+		},
+		{
+			name: "Retry delay in seconds",
+			err: fmt.Errorf("failed to read resource: %w", &azcore.ResponseError{
+				StatusCode: http.StatusTooManyRequests,
+				RawResponse: &http.Response{
+					Header: http.Header{"Retry-After": []string{"3"}},
+				},
+			}),
+			expectedRetryAfter: 3 * time.Second,
+			expectedThrottled:  true,
+		},
+		{
+			name: "No retry delay",
+			err: &azcore.ResponseError{
+				StatusCode: http.StatusTooManyRequests,
+			},
+			expectedRetryAfter: 0,
+			expectedThrottled:  true,
+		},
+		{
+			name: "Other response error",
+			err: &azcore.ResponseError{
+				StatusCode: http.StatusServiceUnavailable,
+			},
+			expectedThrottled: false,
+		},
+		{
+			name:              "Other error",
+			err:               errors.New("connection refused"),
+			expectedThrottled: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryAfter, throttled := IsThrottledError(tt.err)
+			if throttled != tt.expectedThrottled {
+				t.Errorf("expected throttled %t, got %t", tt.expectedThrottled, throttled)
+			}
+			if retryAfter != tt.expectedRetryAfter {
+				t.Errorf("expected retry after %s, got %s", tt.expectedRetryAfter, retryAfter)
+			}
+		})
+	}
+}
+
+const fakeCosmosEndpoint = "https://fake.documents.azure.com:443/"
+
+// fakeCosmosTransport answers Cosmos DB queries with a fixed set of
+// documents and records the body of each query it receives.
+type fakeCosmosTransport struct {
+	documents []any
+	queries   []string
+}
+
+func (t *fakeCosmosTransport) Do(request *http.Request) (*http.Response, error) {
+	var body any
+
+	if strings.HasSuffix(request.URL.Path, "/docs") {
+		data, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		t.queries = append(t.queries, string(data))
+		body = map[string]any{
+			"_rid":      "fake",
+			"Documents": t.documents,
+			"_count":    len(t.documents),
+		}
+	} else {
+		// Account properties, requested by the client to
+		// discover the regional endpoints.
+		location := map[string]any{"name": "fake", "databaseAccountEndpoint": fakeCosmosEndpoint}
+		body = map[string]any{
+			"id":                "fake",
+			"writableLocations": []any{location},
+			"readableLocations": []any{location},
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("x-ms-request-charge", "1")
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(data)),
+		Request:    request,
+	}, nil
+}
+
+func newFakeCosmosDBClient(t *testing.T, transport policy.Transporter) *CosmosDBClient {
+	credential, err := azcosmos.NewKeyCredential("ZmFrZQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := azcosmos.NewClientWithKey(fakeCosmosEndpoint, credential, &azcosmos.ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Transport: transport,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := client.NewContainer("fake", resourcesContainer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &CosmosDBClient{resources: resources}
+}
+
+func TestGetResourceDocs(t *testing.T) {
+	var documents []any
+	var internalIDs []ocm.InternalID
+
+	for _, name := range []string{"cluster1", "cluster2", "cluster3"} {
+		resourceID, err := arm.ParseResourceID("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/testGroup/providers/Microsoft.RedHatOpenShift/hcpOpenShiftClusters/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc := NewResourceDocument(resourceID)
+		doc.InternalID, err = ocm.NewInternalID(ocm.GenerateClusterHREF(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		documents = append(documents, doc)
+		internalIDs = append(internalIDs, doc.InternalID)
+	}
+
+	transport := &fakeCosmosTransport{documents: documents}
+	client := newFakeCosmosDBClient(t, transport)
+
+	// Duplicate IDs should not be sent twice.
+	docs, err := client.GetResourceDocs(context.Background(), append(internalIDs, internalIDs[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(transport.queries) != 1 {
+		t.Fatalf("expected 1 query, got %d", len(transport.queries))
+	}
+
+	var query struct {
+		Query      string                    `json:"query"`
+		Parameters []azcosmos.QueryParameter `json:"parameters"`
+	}
+	err = json.Unmarshal([]byte(transport.queries[0]), &query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedQuery := "SELECT * FROM c WHERE c.internalId IN (@internalId0, @internalId1, @internalId2)"
+	if query.Query != expectedQuery {
+		t.Errorf("expected query '%s', got '%s'", expectedQuery, query.Query)
+	}
+	if len(query.Parameters) != len(internalIDs) {
+		t.Errorf("expected %d query parameters, got %d", len(internalIDs), len(query.Parameters))
+	}
+
+	for _, internalID := range internalIDs {
+		if _, ok := docs[internalID]; !ok {
+			t.Errorf("expected resource document for '%s'", internalID.String())
+		}
+	}
+}
+
+func TestGetResourceDocsEmpty(t *testing.T) {
+	transport := &fakeCosmosTransport{}
+	client := newFakeCosmosDBClient(t, transport)
+
+	docs, err := client.GetResourceDocs(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 0 {
+		t.Errorf("expected no resource documents, got %d", len(docs))
+	}
+	if len(transport.queries) != 0 {
+		t.Errorf("expected no queries, got %d", len(transport.queries))
+	}
+}
+
+func TestOperationDocumentTimeToLive(t *testing.T) {
+	const terminalTimeToLive = time.Hour
+
+	tests := []struct {
+		name               string
+		status             arm.ProvisioningState
+		terminalTimeToLive time.Duration
+		expectedTimeToLive int32
+	}{
+		{
+			name:               "Accepted never expires",
+			status:             arm.ProvisioningStateAccepted,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: -1,
+		},
+		{
+			name:               "Provisioning never expires",
+			status:             arm.ProvisioningStateProvisioning,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: -1,
+		},
+		{
+			name:               "Deleting never expires",
+			status:             arm.ProvisioningStateDeleting,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: -1,
+		},
+		{
+			name:               "Succeeded expires",
+			status:             arm.ProvisioningStateSucceeded,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: 3600,
+		},
+		{
+			name:               "Failed expires",
+			status:             arm.ProvisioningStateFailed,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: 3600,
+		},
+		{
+			name:               "Canceled expires",
+			status:             arm.ProvisioningStateCanceled,
+			terminalTimeToLive: terminalTimeToLive,
+			expectedTimeToLive: 3600,
+		},
+		{
+			name:               "Terminal defers to container default",
+			status:             arm.ProvisioningStateSucceeded,
+			expectedTimeToLive: 0,
+		},
+		{
+			name:               "Active never expires without a terminal time-to-live",
+			status:             arm.ProvisioningStateProvisioning,
+			expectedTimeToLive: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewOperationDocument(OperationRequestCreate, nil, ocm.InternalID{})
+			doc.setTimeToLive(tt.terminalTimeToLive)
+			if doc.TimeToLive != -1 {
+				t.Errorf("expected new operation to never expire, got time-to-live %d", doc.TimeToLive)
+			}
+
+			doc.UpdateStatus(tt.status, nil)
+			doc.setTimeToLive(tt.terminalTimeToLive)
+			if doc.TimeToLive != tt.expectedTimeToLive {
+				t.Errorf("expected time-to-live %d, got %d", tt.expectedTimeToLive, doc.TimeToLive)
+			}
+		})
+	}
+}
+
+func TestOperationDocumentUpdateStatus(t *testing.T) {
+	tests := []struct {
+		name            string
+		current         arm.ProvisioningState
+		status          arm.ProvisioningState
+		expectedUpdated bool
+		expectedStatus  arm.ProvisioningState
+	}{
+		{
+			name:            "Active status changes",
+			current:         arm.ProvisioningStateAccepted,
+			status:          arm.ProvisioningStateProvisioning,
+			expectedUpdated: true,
+			expectedStatus:  arm.ProvisioningStateProvisioning,
+		},
+		{
+			name:            "Same status is a no-op",
+			current:         arm.ProvisioningStateProvisioning,
+			status:          arm.ProvisioningStateProvisioning,
+			expectedUpdated: false,
+			expectedStatus:  arm.ProvisioningStateProvisioning,
+		},
+		{
+			name:            "Terminal status changes",
+			current:         arm.ProvisioningStateSucceeded,
+			status:          arm.ProvisioningStateDeleting,
+			expectedUpdated: true,
+			expectedStatus:  arm.ProvisioningStateDeleting,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := NewOperationDocument(OperationRequestCreate, nil, ocm.InternalID{})
+			doc.Status = tt.current
+
+			updated := doc.UpdateStatus(tt.status, nil)
+			if updated != tt.expectedUpdated {
+				t.Errorf("expected updated %t, got %t", tt.expectedUpdated, updated)
+			}
+			if doc.Status != tt.expectedStatus {
+				t.Errorf("expected status %s, got %s", tt.expectedStatus, doc.Status)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ package database
 // Licensed under the Apache License 2.0.
 
 import (
+	"slices"
 	"strings"
 	"time"
 
@@ -54,6 +55,58 @@ func NewResourceDocument(resourceID *arm.ResourceID) *ResourceDocument {
 	}
 }
 
+// ResourceDocumentFilter narrows the set of resource documents returned
+// by ListResourceDocs. Zero-valued fields do not restrict the results.
+type ResourceDocumentFilter struct {
+	// ProvisioningState matches documents with the given provisioning
+	// state. The comparison is case-insensitive.
+	ProvisioningState arm.ProvisioningState
+	// Tags matches documents having every given tag key and value.
+	Tags map[string]string
+}
+
+// Matches returns true if the document satisfies the filter.
+// A nil filter matches every document.
+func (f *ResourceDocumentFilter) Matches(doc *ResourceDocument) bool {
+	if f == nil {
+		return true
+	}
+	if f.ProvisioningState != "" && !strings.EqualFold(string(f.ProvisioningState), string(doc.ProvisioningState)) {
+		return false
+	}
+	for key, value := range f.Tags {
+		if tagValue, ok := doc.Tags[key]; !ok || tagValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// OperationDocumentFilter narrows the set of operation documents returned
+// by ListOperationDocs. Zero-valued fields do not restrict the results.
+type OperationDocumentFilter struct {
+	// Statuses matches documents having any of the given statuses.
+	Statuses []arm.ProvisioningState
+	// ExternalID matches documents for operations on the given resource.
+	// The comparison is case-insensitive.
+	ExternalID *arm.ResourceID
+}
+
+// Matches returns true if the document satisfies the filter.
+// A nil filter matches every document.
+func (f *OperationDocumentFilter) Matches(doc *OperationDocument) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, doc.Status) {
+		return false
+	}
+	if f.ExternalID != nil && (doc.ExternalID == nil || !strings.EqualFold(f.ExternalID.String(), doc.ExternalID.String())) {
+		return false
+	}
+	return true
+}
+
 type OperationRequest string
 
 const (
@@ -83,6 +136,12 @@ type OperationDocument struct {
 	// NotificationURI is provided by the Azure-AsyncNotificationUri header if the
 	// Async Operation Callbacks ARM feature is enabled
 	NotificationURI string `json:"notificationUri,omitempty"`
+	// ClientRequestID is the x-ms-client-request-id header of the request
+	// that started the operation
+	ClientRequestID string `json:"clientRequestId,omitempty"`
+	// CorrelationRequestID is the x-ms-correlation-request-id header of the
+	// request that started the operation
+	CorrelationRequestID string `json:"correlationRequestId,omitempty"`
 
 	// StartTime marks the start of the operation
 	StartTime time.Time `json:"startTime,omitempty"`
@@ -93,6 +152,14 @@ type OperationDocument struct {
 	Status arm.ProvisioningState `json:"status,omitempty"`
 	// Error is an OData error, present when Status is "Failed" or "Canceled"
 	Error *arm.CloudErrorBody `json:"error,omitempty"`
+	// PercentComplete is a coarse estimate of the operation's progress, when
+	// one can be derived from Cluster Service. Zero means no estimate.
+	PercentComplete float64 `json:"percentComplete,omitempty"`
+
+	// TimeToLive is the Cosmos DB item time-to-live in seconds. It is -1,
+	// meaning never expire, while the operation is active. Zero defers to
+	// the container's default time-to-live.
+	TimeToLive int32 `json:"ttl,omitempty"`
 }
 
 func NewOperationDocument(request OperationRequest, externalID *arm.ResourceID, internalID ocm.InternalID) *OperationDocument {
@@ -118,14 +185,25 @@ func NewOperationDocument(request OperationRequest, externalID *arm.ResourceID,
 	return doc
 }
 
+// SetCorrelationData records the identifiers of the request that started
+// the operation so the operation can be traced back to it.
+func (doc *OperationDocument) SetCorrelationData(correlationData *arm.CorrelationData) {
+	if correlationData == nil {
+		return
+	}
+	doc.ClientRequestID = correlationData.ClientRequestID
+	doc.CorrelationRequestID = correlationData.CorrelationRequestID
+}
+
 // ToStatus converts an OperationDocument to the ARM operation status format.
 func (doc *OperationDocument) ToStatus() *arm.Operation {
 	operation := &arm.Operation{
-		ID:        doc.OperationID,
-		Name:      doc.OperationID.Name,
-		Status:    doc.Status,
-		StartTime: &doc.StartTime,
-		Error:     doc.Error,
+		ID:              doc.OperationID,
+		Name:            doc.OperationID.Name,
+		Status:          doc.Status,
+		StartTime:       &doc.StartTime,
+		PercentComplete: doc.PercentComplete,
+		Error:           doc.Error,
 	}
 
 	if doc.Status.IsTerminal() {
@@ -149,6 +227,33 @@ func (doc *OperationDocument) UpdateStatus(status arm.ProvisioningState, err *ar
 	return false
 }
 
+// setTimeToLive sets the item time-to-live according to the operation
+// status. Active operations never expire. Operations in a terminal state
+// expire after the given duration, or after the container's default
+// time-to-live if the duration is not positive.
+func (doc *OperationDocument) setTimeToLive(terminalTimeToLive time.Duration) {
+	switch {
+	case !doc.Status.IsTerminal():
+		doc.TimeToLive = -1
+	case terminalTimeToLive > 0:
+		doc.TimeToLive = int32(terminalTimeToLive.Seconds())
+	default:
+		doc.TimeToLive = 0
+	}
+}
+
+// UpdatePercentComplete conditionally updates the document if the percentage
+// given is greater than the percentage already present, so that reported
+// progress never goes backwards. Returns true if the document was updated.
+// This is intended to be used with DBClient.UpdateOperationDoc.
+func (doc *OperationDocument) UpdatePercentComplete(percentComplete float64) bool {
+	if percentComplete > doc.PercentComplete {
+		doc.PercentComplete = min(percentComplete, 100)
+		return true
+	}
+	return false
+}
+
 // SubscriptionDocument represents an Azure Subscription document.
 type SubscriptionDocument struct {
 	BaseDocument
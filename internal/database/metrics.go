@@ -0,0 +1,56 @@
+package database
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Outcomes of a single attempt at an optimistic concurrency transaction.
+const (
+	transactionOutcomeSuccess  = "success"
+	transactionOutcomeConflict = "conflict"
+	transactionOutcomeError    = "error"
+)
+
+// newTransactionAttemptsCounter returns a counter of attempts to replace a
+// container item under an etag precondition, registered with registerer if
+// it is not nil. Attempts with a "conflict" outcome are retried, so a high
+// conflict rate for a container points at contention on its items.
+func newTransactionAttemptsCounter(registerer prometheus.Registerer) (*prometheus.CounterVec, error) {
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "database_transaction_attempts_total",
+			Help: "Number of attempts to replace a database item under an etag precondition, by container and outcome.",
+		},
+		[]string{"container", "outcome"})
+
+	if registerer != nil {
+		err := registerer.Register(counter)
+		if err != nil {
+			var alreadyRegistered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &alreadyRegistered) {
+				return nil, err
+			}
+			counter = alreadyRegistered.ExistingCollector.(*prometheus.CounterVec)
+		}
+	}
+
+	return counter, nil
+}
+
+// recordTransactionAttempt counts an attempt to replace an item in the
+// given container, logging conflicts at debug level. The context logger
+// is expected to identify the item.
+func (d *CosmosDBClient) recordTransactionAttempt(ctx context.Context, container string, try int, outcome string) {
+	d.transactionAttempts.WithLabelValues(container, outcome).Inc()
+
+	if outcome == transactionOutcomeConflict {
+		loggerFromContext(ctx).Debug(fmt.Sprintf("Etag conflict replacing %s container item on attempt %d", container, try+1))
+	}
+}
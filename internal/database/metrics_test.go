@@ -0,0 +1,72 @@
+package database
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordTransactionAttempt(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	transactionAttempts, err := newTransactionAttemptsCounter(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Registering again must reuse the existing counter.
+	again, err := newTransactionAttemptsCounter(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != transactionAttempts {
+		t.Error("expected the already registered counter to be reused")
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := ContextWithLogger(context.Background(), logger)
+
+	d := &CosmosDBClient{transactionAttempts: transactionAttempts}
+	d.recordTransactionAttempt(ctx, resourcesContainer, 0, transactionOutcomeConflict)
+	d.recordTransactionAttempt(ctx, resourcesContainer, 1, transactionOutcomeSuccess)
+	d.recordTransactionAttempt(ctx, operationsContainer, 0, transactionOutcomeError)
+
+	for _, tt := range []struct {
+		container string
+		outcome   string
+		expected  float64
+	}{
+		{resourcesContainer, transactionOutcomeConflict, 1},
+		{resourcesContainer, transactionOutcomeSuccess, 1},
+		{operationsContainer, transactionOutcomeError, 1},
+		{operationsContainer, transactionOutcomeConflict, 0},
+	} {
+		got := testutil.ToFloat64(transactionAttempts.WithLabelValues(tt.container, tt.outcome))
+		if got != tt.expected {
+			t.Errorf("%s/%s: expected %v attempts, got %v", tt.container, tt.outcome, tt.expected, got)
+		}
+	}
+
+	if n := strings.Count(buf.String(), "Etag conflict"); n != 1 {
+		t.Errorf("expected 1 conflict log entry through the context logger, got %d:\n%s", n, buf.String())
+	}
+}
+
+func TestNewTransactionAttemptsCounterUnregistered(t *testing.T) {
+	transactionAttempts, err := newTransactionAttemptsCounter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transactionAttempts == nil {
+		t.Fatal("expected a counter even without a registerer")
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 type ClusterListIterator struct {
 	request *cmv1.ClustersListRequest
+	items   []*cmv1.Cluster
 	err     error
 }
 
@@ -21,7 +22,13 @@ type ClusterListIterator struct {
 func (iter ClusterListIterator) Items(ctx context.Context) iter.Seq[*cmv1.Cluster] {
 	return func(yield func(*cmv1.Cluster) bool) {
 		// Request can be nil to allow for mocking.
-		if iter.request != nil {
+		if iter.request == nil {
+			for _, item := range iter.items {
+				if !yield(item) {
+					return
+				}
+			}
+		} else {
 			var page int = 0
 			var count int = 0
 			var total int = math.MaxInt
@@ -67,6 +74,7 @@ func (iter ClusterListIterator) GetError() error {
 
 type NodePoolListIterator struct {
 	request *cmv1.NodePoolsListRequest
+	items   []*cmv1.NodePool
 	err     error
 }
 
@@ -75,7 +83,13 @@ type NodePoolListIterator struct {
 func (iter NodePoolListIterator) Items(ctx context.Context) iter.Seq[*cmv1.NodePool] {
 	return func(yield func(*cmv1.NodePool) bool) {
 		// Request can be nil to allow for mocking.
-		if iter.request != nil {
+		if iter.request == nil {
+			for _, item := range iter.items {
+				if !yield(item) {
+					return
+				}
+			}
+		} else {
 			var page int = 0
 			var count int = 0
 			var total int = math.MaxInt
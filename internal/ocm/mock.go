@@ -6,6 +6,7 @@ package ocm
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
@@ -41,6 +42,10 @@ func NewMockClusterServiceClient() MockClusterServiceClient {
 
 func (mcsc *MockClusterServiceClient) GetConn() *sdk.Connection { panic("GetConn not implemented") }
 
+func (mcsc *MockClusterServiceClient) CSConnectionTest(ctx context.Context) error {
+	return nil
+}
+
 func (csc *MockClusterServiceClient) AddProperties(builder *cmv1.ClusterBuilder) *cmv1.ClusterBuilder {
 	additionalProperties := map[string]string{}
 	return builder.Properties(additionalProperties)
@@ -57,9 +62,9 @@ func (mcsc *MockClusterServiceClient) GetCSCluster(ctx context.Context, internal
 
 func (mcsc *MockClusterServiceClient) PostCSCluster(ctx context.Context, cluster *cmv1.Cluster) (*cmv1.Cluster, error) {
 	href := GenerateClusterHREF(cluster.Name())
-	// Adding the HREF to correspond with what the full client does when crating the body
+	// Adding the ID and HREF to correspond with what the full client does when crating the body
 	clusterBuilder := cmv1.NewCluster()
-	enrichedCluster, err := clusterBuilder.Copy(cluster).HREF(href).Build()
+	enrichedCluster, err := clusterBuilder.Copy(cluster).ID(cluster.Name()).HREF(href).Build()
 	if err != nil {
 		return nil, err
 	}
@@ -91,8 +96,14 @@ func (mcsc *MockClusterServiceClient) DeleteCSCluster(ctx context.Context, inter
 	return nil
 }
 
+// ListCSClusters ignores the search expression and returns all clusters.
+// Callers are expected to filter the results as needed.
 func (mcsc *MockClusterServiceClient) ListCSClusters(searchExpression string) ClusterListIterator {
-	return ClusterListIterator{err: fmt.Errorf("ListCSClusters not implemented")}
+	items := make([]*cmv1.Cluster, 0, len(mcsc.clusters))
+	for _, cluster := range mcsc.clusters {
+		items = append(items, cluster)
+	}
+	return ClusterListIterator{items: items}
 }
 
 func (mcsc *MockClusterServiceClient) GetCSNodePool(ctx context.Context, internalID InternalID) (*cmv1.NodePool, error) {
@@ -138,6 +149,14 @@ func (mcsc *MockClusterServiceClient) DeleteCSNodePool(ctx context.Context, inte
 	return nil
 }
 
+// ListCSNodePools ignores the search expression and returns all node pools
+// of the cluster. Callers are expected to filter the results as needed.
 func (mcsc *MockClusterServiceClient) ListCSNodePools(clusterInternalID InternalID, searchExpression string) NodePoolListIterator {
-	return NodePoolListIterator{err: fmt.Errorf("ListCSClusters not implemented")}
+	items := make([]*cmv1.NodePool, 0)
+	for internalID, nodePool := range mcsc.nodePools {
+		if strings.HasPrefix(internalID.path, clusterInternalID.path+"/") {
+			items = append(items, nodePool)
+		}
+	}
+	return NodePoolListIterator{items: items}
 }
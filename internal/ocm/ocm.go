@@ -6,6 +6,7 @@ package ocm
 import (
 	"context"
 	"fmt"
+	"time"
 
 	sdk "github.com/openshift-online/ocm-sdk-go"
 	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
@@ -13,6 +14,7 @@ import (
 
 type ClusterServiceClientSpec interface {
 	GetConn() *sdk.Connection
+	CSConnectionTest(ctx context.Context) error
 	AddProperties(builder *cmv1.ClusterBuilder) *cmv1.ClusterBuilder
 	GetCSCluster(ctx context.Context, internalID InternalID) (*cmv1.Cluster, error)
 	PostCSCluster(ctx context.Context, cluster *cmv1.Cluster) (*cmv1.Cluster, error)
@@ -41,10 +43,27 @@ type ClusterServiceClient struct {
 	// ProvisionerNoOpDeprovision sets the provisioner_noop_deprovision property for all cluster requests to Cluster
 	// Service, which short-circuits the full deprovision flow during testing
 	ProvisionerNoOpDeprovision bool
+
+	// MaxAttempts bounds the number of times a request failing with a transient
+	// error is sent to Cluster Service. Values less than 1 use DefaultMaxAttempts.
+	// Retries also stop at the deadline of the request context.
+	MaxAttempts int
+
+	// retryBaseDelay overrides the initial delay between attempts, for testing.
+	retryBaseDelay time.Duration
 }
 
 func (csc *ClusterServiceClient) GetConn() *sdk.Connection { return csc.Conn }
 
+// CSConnectionTest checks Cluster Service is reachable by fetching the
+// clusters_mgmt API metadata, which is cheap enough for frequent health checks.
+func (csc *ClusterServiceClient) CSConnectionTest(ctx context.Context) error {
+	if _, err := csc.Conn.ClustersMgmt().V1().Get().SendContext(ctx); err != nil {
+		return fmt.Errorf("failed to get Cluster Service metadata during healthcheck: %w", err)
+	}
+	return nil
+}
+
 // AddProperties injects the some additional properties into the CSCluster Object.
 func (csc *ClusterServiceClient) AddProperties(builder *cmv1.ClusterBuilder) *cmv1.ClusterBuilder {
 	additionalProperties := map[string]string{}
@@ -66,7 +85,9 @@ func (csc *ClusterServiceClient) GetCSCluster(ctx context.Context, internalID In
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a cluster: %s", internalID)
 	}
-	clusterGetResponse, err := client.Get().SendContext(ctx)
+	clusterGetResponse, err := withRetry(ctx, csc, isTransientError, func() (*cmv1.ClusterGetResponse, error) {
+		return client.Get().SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -83,7 +104,9 @@ func (csc *ClusterServiceClient) GetCSClusterStatus(ctx context.Context, interna
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a cluster: %s", internalID)
 	}
-	clusterStatusGetResponse, err := client.Status().Get().SendContext(ctx)
+	clusterStatusGetResponse, err := withRetry(ctx, csc, isTransientError, func() (*cmv1.ClusterStatusGetResponse, error) {
+		return client.Status().Get().SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -94,9 +117,13 @@ func (csc *ClusterServiceClient) GetCSClusterStatus(ctx context.Context, interna
 	return status, nil
 }
 
-// PostCSCluster creates and sends a POST request to create a cluster in Clusters Service
+// PostCSCluster creates and sends a POST request to create a cluster in Clusters Service.
+// The request is only retried if it never reached Clusters Service, since a repeated
+// POST could create a second cluster.
 func (csc *ClusterServiceClient) PostCSCluster(ctx context.Context, cluster *cmv1.Cluster) (*cmv1.Cluster, error) {
-	clustersAddResponse, err := csc.Conn.ClustersMgmt().V1().Clusters().Add().Body(cluster).SendContext(ctx)
+	clustersAddResponse, err := withRetry(ctx, csc, isUnsentRequestError, func() (*cmv1.ClustersAddResponse, error) {
+		return csc.Conn.ClustersMgmt().V1().Clusters().Add().Body(cluster).SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +140,9 @@ func (csc *ClusterServiceClient) UpdateCSCluster(ctx context.Context, internalID
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a cluster: %s", internalID)
 	}
-	clusterUpdateResponse, err := client.Update().Body(cluster).SendContext(ctx)
+	clusterUpdateResponse, err := withRetry(ctx, csc, isTransientError, func() (*cmv1.ClusterUpdateResponse, error) {
+		return client.Update().Body(cluster).SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +159,9 @@ func (csc *ClusterServiceClient) DeleteCSCluster(ctx context.Context, internalID
 	if !ok {
 		return fmt.Errorf("OCM path is not a cluster: %s", internalID)
 	}
-	_, err := client.Delete().SendContext(ctx)
+	_, err := withDeleteRetry(ctx, csc, func() (*cmv1.ClusterDeleteResponse, error) {
+		return client.Delete().SendContext(ctx)
+	})
 	return err
 }
 
@@ -151,7 +182,9 @@ func (csc *ClusterServiceClient) GetCSNodePool(ctx context.Context, internalID I
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a node pool: %s", internalID)
 	}
-	nodePoolGetResponse, err := client.Get().SendContext(ctx)
+	nodePoolGetResponse, err := withRetry(ctx, csc, isTransientError, func() (*cmv1.NodePoolGetResponse, error) {
+		return client.Get().SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -162,13 +195,16 @@ func (csc *ClusterServiceClient) GetCSNodePool(ctx context.Context, internalID I
 	return nodePool, nil
 }
 
-// PostCSNodePool creates and sends a POST request to create a node pool in Clusters Service
+// PostCSNodePool creates and sends a POST request to create a node pool in Clusters Service.
+// Like PostCSCluster, the request is only retried if it never reached Clusters Service.
 func (csc *ClusterServiceClient) PostCSNodePool(ctx context.Context, clusterInternalID InternalID, nodePool *cmv1.NodePool) (*cmv1.NodePool, error) {
 	client, ok := clusterInternalID.GetClusterClient(csc.Conn)
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a cluster: %s", clusterInternalID)
 	}
-	nodePoolsAddResponse, err := client.NodePools().Add().Body(nodePool).SendContext(ctx)
+	nodePoolsAddResponse, err := withRetry(ctx, csc, isUnsentRequestError, func() (*cmv1.NodePoolsAddResponse, error) {
+		return client.NodePools().Add().Body(nodePool).SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +221,9 @@ func (csc *ClusterServiceClient) UpdateCSNodePool(ctx context.Context, internalI
 	if !ok {
 		return nil, fmt.Errorf("OCM path is not a node pool: %s", internalID)
 	}
-	nodePoolUpdateResponse, err := client.Update().Body(nodePool).SendContext(ctx)
+	nodePoolUpdateResponse, err := withRetry(ctx, csc, isTransientError, func() (*cmv1.NodePoolUpdateResponse, error) {
+		return client.Update().Body(nodePool).SendContext(ctx)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -202,7 +240,9 @@ func (csc *ClusterServiceClient) DeleteCSNodePool(ctx context.Context, internalI
 	if !ok {
 		return fmt.Errorf("OCM path is not a node pool: %s", internalID)
 	}
-	_, err := client.Delete().SendContext(ctx)
+	_, err := withDeleteRetry(ctx, csc, func() (*cmv1.NodePoolDeleteResponse, error) {
+		return client.Delete().SendContext(ctx)
+	})
 	return err
 }
 
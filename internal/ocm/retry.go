@@ -0,0 +1,127 @@
+package ocm
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+)
+
+const (
+	// DefaultMaxAttempts is the number of times a Cluster Service
+	// request failing with a transient error is attempted, unless
+	// ClusterServiceClient.MaxAttempts says otherwise.
+	DefaultMaxAttempts = 3
+
+	// Bounds for the delay between attempts. The delay grows
+	// exponentially from the base delay with full jitter.
+	defaultRetryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay         = 5 * time.Second
+)
+
+// isTransientError returns true if err is a Cluster Service request failure
+// that may succeed if the request is sent again: a server error, throttling,
+// or a broken connection. Client errors such as validation failures are not
+// transient.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var ocmError *ocmerrors.Error
+	if errors.As(err, &ocmError) {
+		status := ocmError.Status()
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+
+	return isUnsentRequestError(err) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// isNotFoundError returns true if err is a Cluster Service "not found" response.
+func isNotFoundError(err error) bool {
+	var ocmError *ocmerrors.Error
+	return errors.As(err, &ocmError) && ocmError.Status() == http.StatusNotFound
+}
+
+// isUnsentRequestError returns true if err shows the request never reached
+// Cluster Service, so even a non-idempotent request is safe to send again.
+func isUnsentRequestError(err error) bool {
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// retryDelay returns a random delay before the given attempt, which
+// counts from 1 for the first retry.
+func retryDelay(baseDelay time.Duration, attempt int) time.Duration {
+	ceiling := retryMaxDelay
+	if shift := attempt - 1; shift < 16 {
+		ceiling = min(baseDelay<<shift, retryMaxDelay)
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// withRetry calls fn until it succeeds, fails with an error the retryable
+// function rejects, or the attempts are exhausted. It gives up early, and
+// returns the last error, if the next attempt could not start before the
+// context deadline.
+func withRetry[T any](ctx context.Context, csc *ClusterServiceClient, retryable func(error) bool, fn func() (T, error)) (T, error) {
+	maxAttempts := csc.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	baseDelay := csc.retryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= maxAttempts || !retryable(err) {
+			return result, err
+		}
+
+		delay := retryDelay(baseDelay, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return result, err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+	}
+}
+
+// withDeleteRetry is withRetry for DELETE requests. A failed attempt may
+// still have deleted the resource, in which case a retry finds it missing.
+// A "not found" response to a retry therefore counts as success.
+func withDeleteRetry[T any](ctx context.Context, csc *ClusterServiceClient, fn func() (T, error)) (T, error) {
+	attempt := 0
+	return withRetry(ctx, csc, isTransientError, func() (T, error) {
+		attempt++
+		result, err := fn()
+		if attempt > 1 && isNotFoundError(err) {
+			return result, nil
+		}
+		return result, err
+	})
+}
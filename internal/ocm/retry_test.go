@@ -0,0 +1,212 @@
+package ocm
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	sdk "github.com/openshift-online/ocm-sdk-go"
+	cmv1 "github.com/openshift-online/ocm-sdk-go/clustersmgmt/v1"
+)
+
+// newFakeClusterService returns a server that responds to the first
+// failures requests with the given status code and to later requests
+// with a cluster, along with a counter of requests received.
+func newFakeClusterService(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		if count <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"kind":"Error","id":"%d","href":"/api/clusters_mgmt/v1/errors/%d","code":"CLUSTERS-MGMT-%d","reason":"Simulated failure"}`, status, status, status)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"kind":"Cluster","id":"test","href":"/api/clusters_mgmt/v1/clusters/test","name":"test"}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	return ts, &requests
+}
+
+func TestClusterServiceClientRetry(t *testing.T) {
+	tests := []struct {
+		name             string
+		post             bool
+		maxAttempts      int
+		failures         int32
+		status           int
+		expectError      bool
+		expectedRequests int32
+	}{
+		{
+			name:             "GET succeeds after transient failures",
+			failures:         2,
+			status:           http.StatusServiceUnavailable,
+			expectedRequests: 3,
+		},
+		{
+			name:             "GET gives up after max attempts",
+			maxAttempts:      2,
+			failures:         5,
+			status:           http.StatusBadGateway,
+			expectError:      true,
+			expectedRequests: 2,
+		},
+		{
+			name:             "GET validation error is not retried",
+			failures:         1,
+			status:           http.StatusBadRequest,
+			expectError:      true,
+			expectedRequests: 1,
+		},
+		{
+			name:             "POST is not retried once sent",
+			post:             true,
+			failures:         1,
+			status:           http.StatusServiceUnavailable,
+			expectError:      true,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, requests := newFakeClusterService(t, tt.failures, tt.status)
+
+			// Disable retries in the SDK to count only our own.
+			conn, err := sdk.NewUnauthenticatedConnectionBuilder().
+				URL(ts.URL).
+				RetryLimit(0).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			csc := &ClusterServiceClient{
+				Conn:           conn,
+				MaxAttempts:    tt.maxAttempts,
+				retryBaseDelay: time.Millisecond,
+			}
+
+			ctx := context.Background()
+			if tt.post {
+				cluster, buildErr := cmv1.NewCluster().Name("test").Build()
+				if buildErr != nil {
+					t.Fatal(buildErr)
+				}
+				_, err = csc.PostCSCluster(ctx, cluster)
+			} else {
+				internalID, idErr := NewInternalID(GenerateClusterHREF("test"))
+				if idErr != nil {
+					t.Fatal(idErr)
+				}
+				_, err = csc.GetCSCluster(ctx, internalID)
+			}
+
+			if (err != nil) != tt.expectError {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if requests.Load() != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests.Load())
+			}
+		})
+	}
+}
+
+func TestDeleteRetryNotFound(t *testing.T) {
+	tests := []struct {
+		name             string
+		statuses         []int
+		expectError      bool
+		expectedRequests int32
+	}{
+		{
+			name:             "Not found on retry is success",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusNotFound},
+			expectedRequests: 2,
+		},
+		{
+			name:             "Not found on first attempt is an error",
+			statuses:         []int{http.StatusNotFound},
+			expectError:      true,
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				count := requests.Add(1)
+				status := tt.statuses[min(int(count), len(tt.statuses))-1]
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(status)
+				fmt.Fprintf(w, `{"kind":"Error","id":"%d","href":"/api/clusters_mgmt/v1/errors/%d","code":"CLUSTERS-MGMT-%d","reason":"Simulated failure"}`, status, status, status)
+			}))
+			defer ts.Close()
+
+			conn, err := sdk.NewUnauthenticatedConnectionBuilder().
+				URL(ts.URL).
+				RetryLimit(0).
+				Build()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			csc := &ClusterServiceClient{
+				Conn:           conn,
+				retryBaseDelay: time.Millisecond,
+			}
+
+			internalID, err := NewInternalID(GenerateClusterHREF("test"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = csc.DeleteCSCluster(context.Background(), internalID)
+			if (err != nil) != tt.expectError {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if requests.Load() != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, requests.Load())
+			}
+		})
+	}
+}
+
+func TestWithRetryContextDeadline(t *testing.T) {
+	csc := &ClusterServiceClient{
+		MaxAttempts: 10,
+	}
+
+	// No retry can start before a deadline that has already passed.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	var attempts int
+	_, err := withRetry(ctx, csc, isTransientError, func() (struct{}, error) {
+		attempts++
+		return struct{}{}, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	})
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("expected 1 attempt, got %d", attempts)
+	}
+}
@@ -0,0 +1,60 @@
+package tracing
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+// StartSpan starts a client span for a call to Cluster Service or Cosmos
+// DB as a child of any span in ctx, using the named tracer from the global
+// tracer provider. The global tracer provider is a no-op unless Setup has
+// installed an exporter.
+func StartSpan(ctx context.Context, tracerName, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err, if any, on the span and ends it. A not found
+// result is an expected answer to a lookup rather than a failure, so it
+// is noted as an attribute without marking the span as errored.
+func EndSpan(span trace.Span, err error) {
+	switch {
+	case err == nil:
+	case isNotFoundError(err):
+		span.SetAttributes(attribute.Bool("aro.not_found", true))
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// isNotFoundError returns true if err reports a missing Cosmos DB item
+// or Cluster Service object.
+func isNotFoundError(err error) bool {
+	if errors.Is(err, database.ErrNotFound) {
+		return true
+	}
+
+	var responseError *azcore.ResponseError
+	if errors.As(err, &responseError) && responseError.StatusCode == http.StatusNotFound {
+		return true
+	}
+
+	var ocmError *ocmerrors.Error
+	return errors.As(err, &ocmError) && ocmError.Status() == http.StatusNotFound
+}
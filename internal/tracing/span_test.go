@@ -0,0 +1,76 @@
+package tracing
+
+// Copyright (c) Microsoft Corporation.
+// Licensed under the Apache License 2.0.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	ocmerrors "github.com/openshift-online/ocm-sdk-go/errors"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Azure/ARO-HCP/internal/database"
+)
+
+func TestEndSpan(t *testing.T) {
+	ocmNotFound, err := ocmerrors.NewError().Status(http.StatusNotFound).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus codes.Code
+		expectedEvents int
+	}{
+		{
+			name:           "Success",
+			err:            nil,
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:           "Database item not found",
+			err:            fmt.Errorf("failed to read item: %w", database.ErrNotFound),
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:           "Cluster Service object not found",
+			err:            ocmNotFound,
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:           "Failure",
+			err:            errors.New("connection reset"),
+			expectedStatus: codes.Error,
+			expectedEvents: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			_, span := tracerProvider.Tracer(t.Name()).Start(context.Background(), "test")
+			EndSpan(span, tt.err)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 ended span, got %d", len(spans))
+			}
+			if spans[0].Status().Code != tt.expectedStatus {
+				t.Errorf("expected status %v, got %v", tt.expectedStatus, spans[0].Status().Code)
+			}
+			if len(spans[0].Events()) != tt.expectedEvents {
+				t.Errorf("expected %d events, got %d", tt.expectedEvents, len(spans[0].Events()))
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	lockRepository = "image-sync/lock"
+	lockTag        = "lock"
+	// lockScope is the token scope needed to manage the lock repository.
+	lockScope = "repository:" + lockRepository + ":pull,push,delete"
+
+	lockHolderAnnotation   = "com.microsoft.aro-hcp.image-sync.holder"
+	lockAcquiredAnnotation = "com.microsoft.aro-hcp.image-sync.acquired"
+	lockRenewedAnnotation  = "com.microsoft.aro-hcp.image-sync.renewed"
+
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociEmptyMediaType    = "application/vnd.oci.empty.v1+json"
+)
+
+// ociEmptyConfig is the OCI "empty" descriptor content, used as the config
+// blob of the lock manifest since the lock carries no image content.
+var ociEmptyConfig = []byte("{}")
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int    `json:"size"`
+}
+
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// errLockLost is the cause of the context returned by Acquire being
+// cancelled when another run has taken over the lock.
+var errLockLost = errors.New("lock was taken over by another run")
+
+func sha256Digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// RegistryLock is an advisory distributed lock, implemented as a marker
+// tag in the target registry. It keeps overlapping image-sync runs from
+// working against the same target registry at the same time.
+//
+// Registries offer no compare-and-swap on tags, so two runs starting at
+// the same moment can both write the tag. Reading the tag back narrows
+// that window but cannot close it; the lock guards against overlapping
+// scheduled runs, not against a determined race.
+//
+// While held, the lock is renewed on a heartbeat. A lock that has not
+// been renewed within the stale timeout is assumed to be left over from
+// a crashed run and is taken over.
+type RegistryLock struct {
+	httpclient        *http.Client
+	baseUrl           string
+	bearerToken       string
+	holder            string
+	staleTimeout      time.Duration
+	heartbeatInterval time.Duration
+	acquired          time.Time
+	digest            string
+
+	stopHeartbeat context.CancelCauseFunc
+	heartbeatDone chan struct{}
+
+	nowImpl func() time.Time
+}
+
+// NewRegistryLock creates a new RegistryLock for the target registry.
+// The bearer token must allow pull, push and delete on the lock repository.
+func NewRegistryLock(cfg *SyncConfig, bearerToken string) *RegistryLock {
+	hostname, _ := os.Hostname()
+	staleTimeout := time.Duration(cfg.LockTimeout) * time.Second
+	return &RegistryLock{
+		httpclient:  &http.Client{Timeout: time.Duration(cfg.RequestTimeout) * time.Second},
+		baseUrl:     fmt.Sprintf("https://%s", cfg.AcrTargetRegistry),
+		bearerToken: bearerToken,
+		holder:      fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano()),
+		// Renew well within the stale timeout so a slow request
+		// or two does not let another run take the lock over.
+		staleTimeout:      staleTimeout,
+		heartbeatInterval: staleTimeout / 3,
+		nowImpl:           time.Now,
+	}
+}
+
+func (l *RegistryLock) do(ctx context.Context, method, path, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, l.baseUrl+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", l.bearerToken))
+	req.Header.Add("Accept", ociManifestMediaType)
+	if contentType != "" {
+		req.Header.Add("Content-Type", contentType)
+	}
+
+	Log().Debugw("Sending request", "method", method, "path", path)
+	resp, err := l.httpclient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	Log().Debugw("Got response", "statuscode", resp.StatusCode)
+	return resp, nil
+}
+
+// getLockManifest returns the current lock manifest, or nil if no lock is held.
+func (l *RegistryLock) getLockManifest(ctx context.Context) (*ociManifest, error) {
+	resp, err := l.do(ctx, http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", lockRepository, lockTag), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var manifest ociManifest
+	err = json.Unmarshal(body, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return &manifest, nil
+}
+
+func (l *RegistryLock) putEmptyConfig(ctx context.Context) error {
+	path := fmt.Sprintf("/v2/%s/blobs/uploads/?digest=%s", lockRepository, url.QueryEscape(sha256Digest(ociEmptyConfig)))
+	resp, err := l.do(ctx, http.MethodPost, path, "application/octet-stream", ociEmptyConfig)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (l *RegistryLock) putLockManifest(ctx context.Context) error {
+	emptyDescriptor := ociDescriptor{
+		MediaType: ociEmptyMediaType,
+		Digest:    sha256Digest(ociEmptyConfig),
+		Size:      len(ociEmptyConfig),
+	}
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		Config:        emptyDescriptor,
+		Layers:        []ociDescriptor{emptyDescriptor},
+		Annotations: map[string]string{
+			lockHolderAnnotation:   l.holder,
+			lockAcquiredAnnotation: l.acquired.UTC().Format(time.RFC3339),
+			lockRenewedAnnotation:  l.nowImpl().UTC().Format(time.RFC3339),
+		},
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %v", err)
+	}
+
+	resp, err := l.do(ctx, http.MethodPut, fmt.Sprintf("/v2/%s/manifests/%s", lockRepository, lockTag), ociManifestMediaType, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	l.digest = sha256Digest(body)
+	return nil
+}
+
+func (l *RegistryLock) deleteLockManifest(ctx context.Context, digest string) error {
+	resp, err := l.do(ctx, http.MethodDelete, fmt.Sprintf("/v2/%s/manifests/%s", lockRepository, digest), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Not found means the lock was taken over as stale, which is fine.
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// isStale returns true if the lock described by manifest was
+// last renewed, or acquired, longer than the stale timeout ago.
+func (l *RegistryLock) isStale(manifest *ociManifest) bool {
+	timestamp, ok := manifest.Annotations[lockRenewedAnnotation]
+	if !ok {
+		timestamp = manifest.Annotations[lockAcquiredAnnotation]
+	}
+	acquired, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		// A lock without a readable timestamp can never expire
+		// on its own, so treat it as stale rather than blocking
+		// every future run.
+		return true
+	}
+	return l.nowImpl().Sub(acquired) > l.staleTimeout
+}
+
+// Acquire takes the lock, returning an error if another run holds it.
+// The returned context is derived from ctx and is cancelled with cause
+// errLockLost if the heartbeat finds the lock taken over by another run.
+func (l *RegistryLock) Acquire(ctx context.Context) (context.Context, error) {
+	manifest, err := l.getLockManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading lock: %w", err)
+	}
+
+	if manifest != nil {
+		holder := manifest.Annotations[lockHolderAnnotation]
+		if !l.isStale(manifest) {
+			return nil, fmt.Errorf("lock is held by %s since %s", holder, manifest.Annotations[lockAcquiredAnnotation])
+		}
+		Log().Warnw("Taking over stale lock", "holder", holder, "acquired", manifest.Annotations[lockAcquiredAnnotation])
+	}
+
+	err = l.putEmptyConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error uploading lock config: %w", err)
+	}
+
+	l.acquired = l.nowImpl()
+	err = l.putLockManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error writing lock: %w", err)
+	}
+
+	// Registries offer no compare-and-swap, so read the lock back.
+	// If two runs raced, only the last writer finds itself the holder.
+	manifest, err = l.getLockManifest(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading lock: %w", err)
+	}
+	if manifest == nil || manifest.Annotations[lockHolderAnnotation] != l.holder {
+		l.digest = ""
+		return nil, fmt.Errorf("lock was acquired concurrently by another run")
+	}
+
+	Log().Infow("Acquired lock", "holder", l.holder)
+
+	heartbeatCtx, cancel := context.WithCancelCause(ctx)
+	l.stopHeartbeat = cancel
+	l.heartbeatDone = make(chan struct{})
+	go l.heartbeat(heartbeatCtx, cancel)
+
+	return heartbeatCtx, nil
+}
+
+// heartbeat renews the lock every heartbeat interval until ctx is done,
+// cancelling ctx with errLockLost if another run has taken the lock over.
+func (l *RegistryLock) heartbeat(ctx context.Context, cancel context.CancelCauseFunc) {
+	defer close(l.heartbeatDone)
+
+	if l.heartbeatInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(l.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := l.renew(ctx)
+			switch {
+			case errors.Is(err, errLockLost):
+				Log().Errorw("Lost lock", "holder", l.holder)
+				cancel(err)
+				return
+			case err != nil && ctx.Err() == nil:
+				// Try again on the next tick; the stale timeout
+				// leaves room for a few failed renewals.
+				Log().Warnw("Error renewing lock", "error", err)
+			}
+		}
+	}
+}
+
+// renew rewrites the lock with a fresh renewal timestamp, returning
+// errLockLost if the lock is no longer held by this run.
+func (l *RegistryLock) renew(ctx context.Context) error {
+	manifest, err := l.getLockManifest(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading lock: %w", err)
+	}
+	if manifest == nil || manifest.Annotations[lockHolderAnnotation] != l.holder {
+		l.digest = ""
+		return errLockLost
+	}
+
+	previousDigest := l.digest
+	err = l.putLockManifest(ctx)
+	if err != nil {
+		return fmt.Errorf("error writing lock: %w", err)
+	}
+
+	// Retagging leaves the previous manifest behind untagged.
+	if previousDigest != l.digest {
+		err = l.deleteLockManifest(ctx, previousDigest)
+		if err != nil {
+			Log().Warnw("Error deleting previous lock manifest", "digest", previousDigest, "error", err)
+		}
+	}
+
+	Log().Debugw("Renewed lock", "holder", l.holder)
+	return nil
+}
+
+// Release stops the heartbeat and gives up the lock. It does nothing
+// if the lock is not held.
+func (l *RegistryLock) Release(ctx context.Context) error {
+	if l.stopHeartbeat != nil {
+		l.stopHeartbeat(nil)
+		<-l.heartbeatDone
+		l.stopHeartbeat = nil
+	}
+
+	if l.digest == "" {
+		return nil
+	}
+
+	err := l.deleteLockManifest(ctx, l.digest)
+	if err != nil {
+		return fmt.Errorf("error releasing lock: %w", err)
+	}
+
+	Log().Infow("Released lock", "holder", l.holder)
+	l.digest = ""
+	return nil
+}
@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// fakeLockRegistry serves just enough of the OCI distribution
+// API for a single repository to exercise RegistryLock.
+type fakeLockRegistry struct {
+	mu       sync.Mutex
+	manifest []byte
+	digest   string
+	puts     int
+}
+
+func (f *fakeLockRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	manifestsPath := "/v2/" + lockRepository + "/manifests/"
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v2/"+lockRepository+"/blobs/uploads/"):
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && r.URL.Path == manifestsPath+lockTag:
+		if f.manifest == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(f.manifest)
+	case r.Method == http.MethodPut && r.URL.Path == manifestsPath+lockTag:
+		body, _ := io.ReadAll(r.Body)
+		f.manifest = body
+		f.digest = sha256Digest(body)
+		f.puts++
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, manifestsPath):
+		if f.manifest == nil || r.URL.Path != manifestsPath+f.digest {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		f.manifest = nil
+		f.digest = ""
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestLock(baseUrl, holder string, now time.Time) *RegistryLock {
+	return &RegistryLock{
+		httpclient:   http.DefaultClient,
+		baseUrl:      baseUrl,
+		holder:       holder,
+		staleTimeout: time.Hour,
+		nowImpl:      func() time.Time { return now },
+	}
+}
+
+func TestRegistryLock(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		heldBy        string
+		heldSince     time.Time
+		expectAcquire bool
+	}{
+		{
+			name:          "not held",
+			expectAcquire: true,
+		},
+		{
+			name:          "held by another run",
+			heldBy:        "other",
+			heldSince:     now.Add(-time.Minute),
+			expectAcquire: false,
+		},
+		{
+			name:          "stale lock",
+			heldBy:        "other",
+			heldSince:     now.Add(-2 * time.Hour),
+			expectAcquire: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := &fakeLockRegistry{}
+			server := httptest.NewServer(registry)
+			defer server.Close()
+
+			if tc.heldBy != "" {
+				other := newTestLock(server.URL, tc.heldBy, tc.heldSince)
+				_, err := other.Acquire(ctx)
+				assert.NilError(t, err)
+			}
+
+			lock := newTestLock(server.URL, "self", now)
+			_, err := lock.Acquire(ctx)
+			if !tc.expectAcquire {
+				assert.ErrorContains(t, err, "lock is held by other")
+				assert.NilError(t, lock.Release(ctx))
+				assert.Assert(t, registry.manifest != nil)
+				return
+			}
+			assert.NilError(t, err)
+
+			var manifest ociManifest
+			assert.NilError(t, json.Unmarshal(registry.manifest, &manifest))
+			assert.Equal(t, manifest.Annotations[lockHolderAnnotation], "self")
+
+			assert.NilError(t, lock.Release(ctx))
+			assert.Assert(t, registry.manifest == nil)
+		})
+	}
+}
+
+func TestRegistryLockHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	registry := &fakeLockRegistry{}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	lock := newTestLock(server.URL, "self", now)
+	lock.heartbeatInterval = 10 * time.Millisecond
+
+	lockedCtx, err := lock.Acquire(ctx)
+	assert.NilError(t, err)
+
+	// Wait for the heartbeat to renew the lock at least once.
+	renewed := false
+	for deadline := time.Now().Add(5 * time.Second); !renewed && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		registry.mu.Lock()
+		renewed = registry.puts > 1
+		registry.mu.Unlock()
+	}
+	assert.Assert(t, renewed, "expected the lock to be renewed")
+
+	assert.NilError(t, lock.Release(ctx))
+	assert.Assert(t, registry.manifest == nil)
+	assert.ErrorIs(t, context.Cause(lockedCtx), context.Canceled)
+}
+
+func TestRegistryLockTakenOver(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	registry := &fakeLockRegistry{}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	// No heartbeat interval, so renewals only happen when called.
+	lock := newTestLock(server.URL, "self", now)
+	_, err := lock.Acquire(ctx)
+	assert.NilError(t, err)
+
+	other := newTestLock(server.URL, "other", now.Add(2*time.Hour))
+	_, err = other.Acquire(ctx)
+	assert.NilError(t, err)
+
+	assert.ErrorIs(t, lock.renew(ctx), errLockLost)
+
+	// The lock now belongs to the other run and must be left alone.
+	assert.NilError(t, lock.Release(ctx))
+	var manifest ociManifest
+	assert.NilError(t, json.Unmarshal(registry.manifest, &manifest))
+	assert.Equal(t, manifest.Annotations[lockHolderAnnotation], "other")
+
+	assert.NilError(t, other.Release(ctx))
+}
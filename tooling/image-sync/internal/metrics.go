@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace = "image_sync"
+
+// Metrics records per repository sync statistics. A nil *Metrics is valid
+// and records nothing, so callers need not check whether metrics are enabled.
+type Metrics struct {
+	copies      *prometheus.CounterVec
+	failures    *prometheus.CounterVec
+	bytes       *prometheus.CounterVec
+	lastSuccess *prometheus.GaugeVec
+}
+
+// NewMetrics creates the sync metrics and registers them with the registerer
+func NewMetrics(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		copies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "copies_total",
+			Help:      "Number of images copied to the target registry.",
+		}, []string{"repository"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "failures_total",
+			Help:      "Number of failed attempts to sync a repository.",
+		}, []string{"repository"}),
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "copied_bytes_total",
+			Help:      "Number of blob bytes transferred to the target registry.",
+		}, []string{"repository"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_success_timestamp_seconds",
+			Help:      "Unix time at which a repository was last synced successfully.",
+		}, []string{"repository"}),
+	}
+
+	registerer.MustRegister(m.copies, m.failures, m.bytes, m.lastSuccess)
+
+	return m
+}
+
+// RecordCopy records an image copied into the repository
+func (m *Metrics) RecordCopy(repository string, bytes uint64) {
+	if m == nil {
+		return
+	}
+	m.copies.WithLabelValues(repository).Inc()
+	m.bytes.WithLabelValues(repository).Add(float64(bytes))
+}
+
+// RecordFailure records a failed attempt to sync the repository
+func (m *Metrics) RecordFailure(repository string) {
+	if m == nil {
+		return
+	}
+	m.failures.WithLabelValues(repository).Inc()
+}
+
+// RecordSuccess records the repository as successfully synced at the given time
+func (m *Metrics) RecordSuccess(repository string, t time.Time) {
+	if m == nil {
+		return
+	}
+	m.lastSuccess.WithLabelValues(repository).Set(float64(t.Unix()))
+}
+
+// WriteMetrics writes the metrics gathered by the gatherer to filename in
+// the Prometheus text format, for the node exporter textfile collector to
+// pick up once the run is over. The file is replaced atomically.
+func WriteMetrics(filename string, gatherer prometheus.Gatherer) error {
+	err := prometheus.WriteToTextfile(filename, gatherer)
+	if err != nil {
+		return err
+	}
+	Log().Infow("Wrote metrics", "file", filename)
+	return nil
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+
+	metrics.RecordCopy("repo", 100)
+	metrics.RecordCopy("repo", 50)
+	metrics.RecordFailure("other")
+	metrics.RecordSuccess("repo", time.Unix(1700000000, 0))
+
+	families, err := registry.Gather()
+	assert.NilError(t, err)
+
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName() + "/" + metric.GetLabel()[0].GetValue()
+			if metric.GetCounter() != nil {
+				values[key] = metric.GetCounter().GetValue()
+			} else {
+				values[key] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	assert.DeepEqual(t, map[string]float64{
+		"image_sync_copies_total/repo":                   2,
+		"image_sync_copied_bytes_total/repo":             150,
+		"image_sync_failures_total/other":                1,
+		"image_sync_last_success_timestamp_seconds/repo": 1700000000,
+	}, values)
+}
+
+func TestNilMetrics(t *testing.T) {
+	var metrics *Metrics
+
+	// Recording on disabled metrics must not panic.
+	metrics.RecordCopy("repo", 100)
+	metrics.RecordFailure("repo")
+	metrics.RecordSuccess("repo", time.Now())
+}
+
+func TestWriteMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics := NewMetrics(registry)
+	metrics.RecordCopy("repo", 100)
+
+	filename := filepath.Join(t.TempDir(), "image_sync.prom")
+	assert.NilError(t, WriteMetrics(filename, registry))
+
+	content, err := os.ReadFile(filename)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), `image_sync_copies_total{repository="repo"} 1`), string(content))
+	assert.Assert(t, strings.Contains(string(content), `image_sync_copied_bytes_total{repository="repo"} 100`), string(content))
+}
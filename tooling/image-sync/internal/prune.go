@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path"
+)
+
+// filterTagsToPrune returns the target tags that are no longer present in the
+// source, skipping tags that match any of the protected glob patterns
+func filterTagsToPrune(src, target, protected []string) []string {
+	var tagsToPrune []string
+
+	srcMap := make(map[string]bool)
+	for _, srcTag := range src {
+		srcMap[srcTag] = true
+	}
+
+	for _, targetTag := range target {
+		if _, ok := srcMap[targetTag]; ok {
+			continue
+		}
+		if isProtectedTag(targetTag, protected) {
+			continue
+		}
+		tagsToPrune = append(tagsToPrune, targetTag)
+	}
+	return tagsToPrune
+}
+
+// isProtectedTag returns true if the tag matches any of the protected glob patterns
+func isProtectedTag(tag string, protected []string) bool {
+	for _, pattern := range protected {
+		if match, err := path.Match(pattern, tag); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPruneThreshold returns an error if deleting the given number of tags
+// would exceed what remains of the per run deletion limit
+func checkPruneThreshold(count, remaining int) error {
+	if count > remaining {
+		return fmt.Errorf("%d stale tags exceed the remaining deletion limit of %d", count, remaining)
+	}
+	return nil
+}
+
+// pruneTarget is the part of the target registry used for pruning
+type pruneTarget interface {
+	GetAllTags(context.Context, string) ([]string, error)
+	DeleteTag(context.Context, string, string) error
+}
+
+// pruneStaleTags deletes tags from the target repository that are no longer
+// present in the source and returns the number of deleted tags. The target is
+// compared against every source tag, not just the newest ones being synced.
+// Nothing is deleted and an error is returned if the number of stale tags
+// exceeds the remaining deletion limit, since that more likely indicates a
+// source outage than genuinely stale tags.
+func pruneStaleTags(ctx context.Context, cfg *SyncConfig, src Registry, target pruneTarget, repoName string, remaining int) (int, error) {
+	srcTags, err := src.GetAllTags(ctx, repoName)
+	if err != nil {
+		return 0, fmt.Errorf("error getting all source tags: %w", err)
+	}
+
+	acrTags, err := target.GetAllTags(ctx, repoName)
+	if err != nil {
+		return 0, fmt.Errorf("error getting ACR tags: %w", err)
+	}
+
+	tagsToPrune := filterTagsToPrune(srcTags, acrTags, cfg.ProtectedTags)
+	if len(tagsToPrune) == 0 {
+		return 0, nil
+	}
+
+	if err := checkPruneThreshold(len(tagsToPrune), remaining); err != nil {
+		return 0, fmt.Errorf("refusing to prune stale tags of %s: %w", repoName, err)
+	}
+
+	Log().Infow("Pruning stale tags", "repository", repoName, "tags", tagsToPrune)
+
+	var pruned int
+	for _, tag := range tagsToPrune {
+		if err := target.DeleteTag(ctx, repoName, tag); err != nil {
+			return pruned, fmt.Errorf("error deleting tag %s: %w", tag, err)
+		}
+		pruned++
+	}
+
+	return pruned, nil
+}
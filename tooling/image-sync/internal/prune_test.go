@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFilterTagsToPrune(t *testing.T) {
+	testCase := []struct {
+		name      string
+		src       []string
+		target    []string
+		protected []string
+		expected  []string
+	}{
+		{
+			name:   "nothing stale",
+			src:    []string{"a", "b", "c"},
+			target: []string{"a", "b"},
+		},
+		{
+			name:     "stale tags",
+			src:      []string{"c", "d"},
+			target:   []string{"a", "b", "c"},
+			expected: []string{"a", "b"},
+		},
+		{
+			name:      "protected tag",
+			src:       []string{"c"},
+			target:    []string{"a", "b", "c"},
+			protected: []string{"b"},
+			expected:  []string{"a"},
+		},
+		{
+			name:      "protected pattern",
+			src:       []string{"v3"},
+			target:    []string{"v1.0", "v1.1", "v2.0", "v3"},
+			protected: []string{"v1.*"},
+			expected:  []string{"v2.0"},
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			tagsToPrune := filterTagsToPrune(tc.src, tc.target, tc.protected)
+			assert.DeepEqual(t, tc.expected, tagsToPrune)
+		})
+	}
+}
+
+func TestCheckPruneThreshold(t *testing.T) {
+	testCase := []struct {
+		name        string
+		count       int
+		remaining   int
+		expectError bool
+	}{
+		{
+			name:      "below threshold",
+			count:     3,
+			remaining: 10,
+		},
+		{
+			name:      "at threshold",
+			count:     10,
+			remaining: 10,
+		},
+		{
+			name:        "above threshold aborts",
+			count:       11,
+			remaining:   10,
+			expectError: true,
+		},
+		{
+			name:        "threshold exhausted",
+			count:       1,
+			remaining:   0,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPruneThreshold(tc.count, tc.remaining)
+			assert.Equal(t, tc.expectError, err != nil)
+		})
+	}
+}
+
+type fakePruneTarget struct {
+	tags    []string
+	deleted []string
+}
+
+func (f *fakePruneTarget) GetAllTags(_ context.Context, _ string) ([]string, error) {
+	return f.tags, nil
+}
+
+func (f *fakePruneTarget) DeleteTag(_ context.Context, _, tag string) error {
+	f.deleted = append(f.deleted, tag)
+	return nil
+}
+
+func TestPruneStaleTags(t *testing.T) {
+	testCase := []struct {
+		name          string
+		target        []string
+		remaining     int
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:      "tags beyond the synced tags are kept",
+			target:    []string{"v1", "v2", "v3", "v4"},
+			remaining: 10,
+		},
+		{
+			name:      "stale tags are deleted",
+			target:    []string{"v1", "v4", "gone1", "gone2"},
+			remaining: 10,
+			expected:  []string{"gone1", "gone2"},
+		},
+		{
+			name:          "over threshold fails without deleting",
+			target:        []string{"v1", "gone1", "gone2"},
+			remaining:     1,
+			expectedError: "refusing to prune stale tags of test: 2 stale tags exceed the remaining deletion limit of 1",
+		},
+	}
+
+	for _, tc := range testCase {
+		t.Run(tc.name, func(t *testing.T) {
+			// The source lists its tags over two pages. Only the newest
+			// tag would be synced with NumberOfTags 1.
+			mock := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Query().Get("last") == "" {
+						w.Header().Set("Link", `</v2/test/tags/list?last=v2>; rel="next"`)
+						_, err := w.Write([]byte(`{"tags":["latest","v1","v2"]}`))
+						assert.NilError(t, err)
+						return
+					}
+					_, err := w.Write([]byte(`{"tags":["v3","v4"]}`))
+					assert.NilError(t, err)
+				}))
+			defer mock.Close()
+
+			src := &OCIRegistry{baseURL: mock.URL, httpclient: mock.Client(), numberOftags: 1}
+			target := &fakePruneTarget{tags: tc.target}
+
+			pruned, err := pruneStaleTags(context.TODO(), &SyncConfig{}, src, target, "test", tc.remaining)
+			if tc.expectedError != "" {
+				assert.Error(t, err, tc.expectedError)
+			} else {
+				assert.NilError(t, err)
+			}
+			assert.Equal(t, len(tc.expected), pruned)
+			assert.DeepEqual(t, tc.expected, target.deleted)
+		})
+	}
+}
@@ -20,7 +20,37 @@ import (
 
 // Registry is the interface for accessing image repositories
 type Registry interface {
+	// GetTags returns the newest tags of a repository, up to the configured number of tags
 	GetTags(context.Context, string) ([]string, error)
+	// GetAllTags returns every tag of a repository
+	GetAllTags(context.Context, string) ([]string, error)
+}
+
+// maxTagPages bounds the number of pages read when listing all tags, to make
+// sure the process does not get stuck
+const maxTagPages = 100
+
+// nextPageURL returns the URL of the next page referenced by the Link
+// header of a paginated registry response, or an empty string if there is
+// none. Relative links are resolved against baseURL.
+func nextPageURL(resp *http.Response, baseURL string) (string, error) {
+	link := resp.Header.Get("Link")
+	if link == "" {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start == -1 || end < start || !strings.Contains(link[end:], `rel="next"`) {
+		return "", fmt.Errorf("unexpected Link header %q", link)
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Link header %q: %v", link, err)
+	}
+	return next.String(), nil
 }
 
 // AuthedTransport is a http.RoundTripper that adds an Authorization header
@@ -129,6 +159,29 @@ func (q *QuayRegistry) GetTags(ctx context.Context, image string) ([]string, err
 	return tags, nil
 }
 
+// GetAllTags returns all tags for the given image
+func (q *QuayRegistry) GetAllTags(ctx context.Context, image string) ([]string, error) {
+	Log().Debugw("Getting all tags for image", "image", image)
+
+	var tags []string
+	for page := 1; page <= maxTagPages; page++ {
+		tagsResponse, err := q.getTagPage(ctx, image, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tags: %v", err)
+		}
+		for _, tag := range tagsResponse.Tags {
+			if tag.Name == "latest" {
+				continue
+			}
+			tags = append(tags, tag.Name)
+		}
+		if !tagsResponse.HasAdditional {
+			return tags, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get tags: more than %d pages", maxTagPages)
+}
+
 type getAccessToken func(context.Context, azcore.TokenCredential) (string, error)
 type getACRUrl func(string) string
 
@@ -247,6 +300,48 @@ func (a *AzureContainerRegistry) GetPullSecret(ctx context.Context) (*AuthSecret
 	return &authSecret, nil
 }
 
+// GetAccessToken exchanges the refresh token returned by GetPullSecret
+// for an access token limited to the given scope
+func (a *AzureContainerRegistry) GetAccessToken(ctx context.Context, refreshToken, scope string) (string, error) {
+	path := fmt.Sprintf("%s/oauth2/token", a.getACRUrlImpl(a.acrName))
+
+	form := url.Values{}
+	form.Add("grant_type", "refresh_token")
+	form.Add("service", a.acrName)
+	form.Add("scope", scope)
+	form.Add("refresh_token", refreshToken)
+
+	Log().Debugw("Creating request", "path", path)
+	req, err := http.NewRequestWithContext(ctx, "POST", path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %v", err)
+	}
+
+	var accessSecret AccessSecret
+	err = json.Unmarshal(body, &accessSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return accessSecret.AccessToken, nil
+}
+
 // EnsureRepositoryExists ensures that the repository exists
 func (a *AzureContainerRegistry) RepositoryExists(ctx context.Context, repository string) (bool, error) {
 
@@ -295,6 +390,38 @@ func (a *AzureContainerRegistry) GetTags(ctx context.Context, repository string)
 	return tags, nil
 }
 
+// GetAllTags returns all tags in the given repository, regardless of the
+// configured number of tags
+func (a *AzureContainerRegistry) GetAllTags(ctx context.Context, repository string) ([]string, error) {
+
+	var tags []string
+
+	pager := a.acrClient.NewListTagsPager(repository, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to advance page: %v", err)
+		}
+		for _, v := range page.Tags {
+			if *v.Name == "latest" {
+				continue
+			}
+			tags = append(tags, *v.Name)
+		}
+	}
+
+	return tags, nil
+}
+
+// DeleteTag deletes a tag from the given repository
+func (a *AzureContainerRegistry) DeleteTag(ctx context.Context, repository, tag string) error {
+	_, err := a.acrClient.DeleteTag(ctx, repository, tag, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %v", err)
+	}
+	return nil
+}
+
 type ACRWithTokenAuth struct {
 	httpclient   *http.Client
 	acrName      string
@@ -399,6 +526,71 @@ func (n *ACRWithTokenAuth) GetTags(ctx context.Context, image string) ([]string,
 	return tagList, nil
 }
 
+// GetAllTags returns all tags for the given image, following the
+// registry's pagination links
+func (n *ACRWithTokenAuth) GetAllTags(ctx context.Context, image string) ([]string, error) {
+	Log().Debugw("Getting all tags for image", "image", image)
+
+	baseURL := fmt.Sprintf("https://%s", n.acrName)
+	path := fmt.Sprintf("%s/acr/v1/%s/_tags?n=100", baseURL, image)
+
+	var tags []string
+	for page := 0; page < maxTagPages; page++ {
+		var acrResponse rawACRTagResponse
+		next, err := getTagsPage(ctx, n.httpclient, path, n.bearerToken, baseURL, &acrResponse)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range acrResponse.Tags {
+			if tag.Name == "latest" {
+				continue
+			}
+			tags = append(tags, tag.Name)
+		}
+		if next == "" {
+			return tags, nil
+		}
+		path = next
+	}
+	return nil, fmt.Errorf("failed to get tags: more than %d pages", maxTagPages)
+}
+
+// getTagsPage requests a single page of a tag listing, unmarshals it into
+// into and returns the URL of the next page, if any
+func getTagsPage(ctx context.Context, client *http.Client, path, bearerToken, baseURL string, into any) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %v", err)
+	}
+	if bearerToken != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", bearerToken))
+	}
+
+	Log().Debugw("Sending request", "path", path)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+	Log().Debugw("Got response", "statuscode", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %v", err)
+	}
+
+	err = json.Unmarshal(body, into)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %v", err)
+	}
+
+	return nextPageURL(resp, baseURL)
+}
+
 // OCIRegistry implements OCI Repository access
 type OCIRegistry struct {
 	httpclient   *http.Client
@@ -498,3 +690,31 @@ func (o *OCIRegistry) GetTags(ctx context.Context, image string) ([]string, erro
 
 	return getNewestTags(&rawOCIResponse, o.numberOftags)
 }
+
+// GetAllTags returns all tags in the given repository, following the
+// registry's pagination links
+func (o *OCIRegistry) GetAllTags(ctx context.Context, image string) ([]string, error) {
+	Log().Debugw("Getting all tags for image", "image", image)
+
+	path := fmt.Sprintf("%s/v2/%s/tags/list", o.baseURL, image)
+
+	var tags []string
+	for page := 0; page < maxTagPages; page++ {
+		var rawOCIResponse rawOCIResponse
+		next, err := getTagsPage(ctx, o.httpclient, path, o.bearerToken, o.baseURL, &rawOCIResponse)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range rawOCIResponse.Tags {
+			if tag == "latest" {
+				continue
+			}
+			tags = append(tags, tag)
+		}
+		if next == "" {
+			return tags, nil
+		}
+		path = next
+	}
+	return nil, fmt.Errorf("failed to get tags: more than %d pages", maxTagPages)
+}
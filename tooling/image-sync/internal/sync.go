@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/containers/image/v5/copy"
 	"github.com/containers/image/v5/docker"
@@ -29,6 +30,10 @@ type SyncConfig struct {
 	RequestTimeout          int
 	AddLatest               bool
 	ManagedIdentityClientID string
+	LockTimeout             int
+	PruneStaleTags          bool
+	MaxPrunePerRun          int
+	ProtectedTags           []string
 }
 type Secrets struct {
 	Registry   string
@@ -50,27 +55,42 @@ func (a AzureSecretFile) BasicAuthEncoded() string {
 	return base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", a.Username, a.Password)))
 }
 
-// Copy copies an image from one registry to another
-func Copy(ctx context.Context, dstreference, srcreference string, dstauth, srcauth *types.DockerAuthConfig) error {
+// Copy copies an image from one registry to another and returns the number of
+// blob bytes transferred. Blobs already present in the target are not counted.
+func Copy(ctx context.Context, dstreference, srcreference string, dstauth, srcauth *types.DockerAuthConfig) (uint64, error) {
 	policyctx, err := signature.NewPolicyContext(&signature.Policy{
 		Default: signature.PolicyRequirements{
 			signature.NewPRInsecureAcceptAnything(),
 		},
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	src, err := docker.ParseReference("//" + srcreference)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	dst, err := docker.ParseReference("//" + dstreference)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	// copy.Image reports each blob it finishes transferring on the
+	// progress channel, but never closes it.
+	progress := make(chan types.ProgressProperties)
+	copied := make(chan uint64)
+	go func() {
+		var bytes uint64
+		for p := range progress {
+			if p.Event == types.ProgressEventDone {
+				bytes += p.Offset
+			}
+		}
+		copied <- bytes
+	}()
+
 	_, err = copy.Image(ctx, policyctx, dst, src, &copy.Options{
 		SourceCtx: &types.SystemContext{
 			DockerAuthConfig: srcauth,
@@ -78,9 +98,12 @@ func Copy(ctx context.Context, dstreference, srcreference string, dstauth, srcau
 		DestinationCtx: &types.SystemContext{
 			DockerAuthConfig: dstauth,
 		},
+		Progress:         progress,
+		ProgressInterval: time.Second,
 	})
+	close(progress)
 
-	return err
+	return <-copied, err
 }
 
 func readBearerSecret(filename string) (*BearerSecret, error) {
@@ -127,8 +150,9 @@ func filterTagsToSync(src, target []string) []string {
 	return tagsToSync
 }
 
-// DoSync syncs the images from the source registry to the target registry
-func DoSync(cfg *SyncConfig) error {
+// DoSync syncs the images from the source registry to the target registry,
+// recording per repository statistics in metrics unless it is nil
+func DoSync(cfg *SyncConfig, metrics *Metrics) error {
 	Log().Infow("Syncing images", "images", cfg.Repositories, "numberoftags", cfg.NumberOfTags)
 	ctx := context.Background()
 
@@ -175,6 +199,27 @@ func DoSync(cfg *SyncConfig) error {
 
 	targetACRAuth := types.DockerAuthConfig{Username: "00000000-0000-0000-0000-000000000000", Password: acrPullSecret.RefreshToken}
 
+	lockToken, err := targetACR.GetAccessToken(ctx, acrPullSecret.RefreshToken, lockScope)
+	if err != nil {
+		return fmt.Errorf("error getting lock access token: %w", err)
+	}
+
+	lock := NewRegistryLock(cfg, lockToken)
+	lockedCtx, err := lock.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error acquiring lock: %w", err)
+	}
+	defer func(ctx context.Context) {
+		if err := lock.Release(ctx); err != nil {
+			Log().Errorw("Error releasing lock", "error", err)
+		}
+	}(ctx)
+
+	// Stop syncing if the lock is lost.
+	ctx = lockedCtx
+
+	pruneRemaining := cfg.MaxPrunePerRun
+
 	for _, repoName := range cfg.Repositories {
 		var srcTags, acrTags []string
 
@@ -183,30 +228,28 @@ func DoSync(cfg *SyncConfig) error {
 
 		Log().Infow("Syncing repository", "repository", repoName, "baseurl", baseURL)
 
-		if client, ok := srcRegistries[baseURL]; ok {
-			srcTags, err = client.GetTags(ctx, repoName)
-			if err != nil {
-				return fmt.Errorf("error getting tags from %s: %w", baseURL, err)
-			}
-			Log().Debugw("Got tags from quay", "tags", srcTags)
-		} else {
+		client, ok := srcRegistries[baseURL]
+		if !ok {
 			// No secret defined, create a default client without auth
-			oci := NewOCIRegistry(cfg, baseURL, "")
-			srcTags, err = oci.GetTags(ctx, repoName)
-			if err != nil {
-				return fmt.Errorf("error getting oci tags: %w", err)
-			}
-			Log().Debugw(fmt.Sprintf("Got tags from %s", baseURL), "repo", repoName, "tags", srcTags)
+			client = NewOCIRegistry(cfg, baseURL, "")
+		}
+		srcTags, err = client.GetTags(ctx, repoName)
+		if err != nil {
+			metrics.RecordFailure(repoName)
+			return fmt.Errorf("error getting tags from %s: %w", baseURL, err)
 		}
+		Log().Debugw(fmt.Sprintf("Got tags from %s", baseURL), "repo", repoName, "tags", srcTags)
 
 		exists, err := targetACR.RepositoryExists(ctx, repoName)
 		if err != nil {
+			metrics.RecordFailure(repoName)
 			return fmt.Errorf("error getting ACR repository information: %w", err)
 		}
 
 		if exists {
 			acrTags, err = targetACR.GetTags(ctx, repoName)
 			if err != nil {
+				metrics.RecordFailure(repoName)
 				return fmt.Errorf("error getting ACR tags: %w", err)
 			}
 			Log().Infow("Got tags from acr", "tags", acrTags)
@@ -223,12 +266,25 @@ func DoSync(cfg *SyncConfig) error {
 			target := fmt.Sprintf("%s/%s:%s", cfg.AcrTargetRegistry, repoName, tagToSync)
 			Log().Infow("Copying images", "images", tagToSync, "from", source, "to", target)
 
-			err = Copy(ctx, target, source, &targetACRAuth, nil)
+			copied, err := Copy(ctx, target, source, &targetACRAuth, nil)
 			if err != nil {
+				metrics.RecordFailure(repoName)
 				return fmt.Errorf("error copying image: %w", err)
 			}
+			metrics.RecordCopy(repoName, copied)
+		}
+
+		if cfg.PruneStaleTags && exists {
+			pruned, err := pruneStaleTags(ctx, cfg, client, targetACR, repoName, pruneRemaining)
+			pruneRemaining -= pruned
+			if err != nil {
+				metrics.RecordFailure(repoName)
+				return fmt.Errorf("error pruning stale tags: %w", err)
+			}
 		}
 
+		metrics.RecordSuccess(repoName, time.Now())
+
 	}
 	return nil
 }
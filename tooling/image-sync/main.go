@@ -2,12 +2,15 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	defaultlog "log"
 	"os"
 	"time"
 
 	"github.com/Azure/ARO-HCP/tooling/image-sync/internal"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -20,16 +23,29 @@ var (
 		Short: "image-sync",
 		Long:  "image-sync",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return internal.DoSync(newSyncConfig())
+			if metricsFile == "" {
+				return internal.DoSync(newSyncConfig(), nil)
+			}
+
+			registry := prometheus.NewRegistry()
+			syncErr := internal.DoSync(newSyncConfig(), internal.NewMetrics(registry))
+
+			// Write metrics for failed runs too, they carry the failure counts.
+			if err := internal.WriteMetrics(metricsFile, registry); err != nil {
+				return errors.Join(syncErr, fmt.Errorf("error writing metrics: %w", err))
+			}
+			return syncErr
 		},
 	}
-	cfgFile  string
-	logLevel string
+	cfgFile     string
+	logLevel    string
+	metricsFile string
 )
 
 func main() {
 	syncCmd.Flags().StringVarP(&cfgFile, "cfgFile", "c", "", "Configuration File")
 	syncCmd.Flags().StringVarP(&logLevel, "logLevel", "l", "", "Loglevel (info, debug, error, warn, fatal, panic)")
+	syncCmd.Flags().StringVar(&metricsFile, "metrics-file", "", "File to write Prometheus metrics to at the end of the run, i.e. for the node exporter textfile collector (disabled if empty)")
 
 	cobra.OnInitialize(configureLogging)
 	cobra.OnInitialize(initConfig)
@@ -56,6 +72,9 @@ func newSyncConfig() *internal.SyncConfig {
 	v.SetDefault("numberoftags", 10)
 	v.SetDefault("requesttimeout", 10)
 	v.SetDefault("addlatest", false)
+	v.SetDefault("locktimeout", 3600)
+	v.SetDefault("prunestaletags", false)
+	v.SetDefault("maxpruneperrun", 10)
 
 	// bind environment variables
 	// we can't use vipers native viper.AutomaticEnv() because it only works
@@ -68,6 +87,10 @@ func newSyncConfig() *internal.SyncConfig {
 		"AcrTargetRegistry":       "ACR_TARGET_REGISTRY",
 		"TenantId":                "TENANT_ID",
 		"ManagedIdentityClientID": "MANAGED_IDENTITY_CLIENT_ID",
+		"LockTimeout":             "LOCK_TIMEOUT",
+		"PruneStaleTags":          "PRUNE_STALE_TAGS",
+		"MaxPrunePerRun":          "MAX_PRUNE_PER_RUN",
+		"ProtectedTags":           "PROTECTED_TAGS",
 	}
 	for key, env := range envVars {
 		if err := v.BindEnv(key, env); err != nil {
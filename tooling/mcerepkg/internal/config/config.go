@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/errors"
+)
+
+const (
+	defaultChartName        = "multicluster-engine"
+	defaultChartDescription = "A Helm chart for multicluster-engine"
+)
+
+// chartNameRegexp matches the chart names recommended by Helm
+var chartNameRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// BundleConfig customizes how an OLM bundle is repackaged as a Helm chart
+type BundleConfig struct {
+	// ChartName overrides the name of the generated chart
+	ChartName string `yaml:"chartName,omitempty"`
+	// ChartDescription overrides the description of the generated chart
+	ChartDescription string `yaml:"chartDescription,omitempty"`
+	// ExcludeManifests lists bundle manifests that are dropped from the chart
+	ExcludeManifests []ManifestRef `yaml:"excludeManifests,omitempty"`
+}
+
+// ManifestRef identifies a bundle manifest by kind and name
+type ManifestRef struct {
+	Kind string `yaml:"kind"`
+	Name string `yaml:"name"`
+}
+
+func (r ManifestRef) String() string {
+	return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+}
+
+func (r ManifestRef) matches(obj unstructured.Unstructured) bool {
+	return obj.GetKind() == r.Kind && obj.GetName() == r.Name
+}
+
+// DefaultBundleConfig returns the configuration used when no config file is given
+func DefaultBundleConfig() *BundleConfig {
+	return &BundleConfig{
+		ChartName:        defaultChartName,
+		ChartDescription: defaultChartDescription,
+	}
+}
+
+// LoadBundleConfig reads and validates the config file at path. Fields not
+// set in the file keep their defaults. An empty path yields the defaults.
+func LoadBundleConfig(path string) (*BundleConfig, error) {
+	cfg := DefaultBundleConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks the config for errors
+func (c *BundleConfig) Validate() error {
+	var errs []error
+	if !chartNameRegexp.MatchString(c.ChartName) {
+		errs = append(errs, fmt.Errorf("chartName %q must consist of lower case alphanumeric characters or '-'", c.ChartName))
+	}
+	if c.ChartDescription == "" {
+		errs = append(errs, fmt.Errorf("chartDescription must not be empty"))
+	}
+	seen := make(map[ManifestRef]bool)
+	for i, ref := range c.ExcludeManifests {
+		if ref.Kind == "" || ref.Name == "" {
+			errs = append(errs, fmt.Errorf("excludeManifests[%d] must set kind and name", i))
+			continue
+		}
+		if seen[ref] {
+			errs = append(errs, fmt.Errorf("excludeManifests[%d] %s is listed more than once", i, ref))
+		}
+		seen[ref] = true
+	}
+	return errors.NewAggregate(errs)
+}
+
+// FilterManifests drops the excluded manifests. Exclusions that match no
+// manifest are an error, since they most likely refer to a manifest renamed
+// or removed in a newer bundle.
+func (c *BundleConfig) FilterManifests(objects []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	matched := make(map[ManifestRef]bool)
+	var filtered []unstructured.Unstructured
+	for _, obj := range objects {
+		excluded := false
+		for _, ref := range c.ExcludeManifests {
+			if ref.matches(obj) {
+				matched[ref] = true
+				excluded = true
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, obj)
+		}
+	}
+
+	var errs []error
+	for _, ref := range c.ExcludeManifests {
+		if !matched[ref] {
+			errs = append(errs, fmt.Errorf("excluded manifest %s not found in the bundle", ref))
+		}
+	}
+	return filtered, errors.NewAggregate(errs)
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+func buildManifest(apiVersion, kind, name string) unstructured.Unstructured {
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+		},
+	}
+}
+
+func bundleManifests() []unstructured.Unstructured {
+	return []unstructured.Unstructured{
+		buildManifest("apps/v1", "Deployment", "multicluster-engine-operator"),
+		buildManifest("console.openshift.io/v1", "ConsolePlugin", "mce"),
+		buildManifest("v1", "Service", "console-mce-console"),
+		buildManifest("v1", "Service", "ocm-webhook"),
+	}
+}
+
+// compareWithFixture compares output with testdata/zz_fixture_<test name>.yaml.
+// Set the UPDATE env var to rewrite the fixture.
+func compareWithFixture(t *testing.T, output []byte) {
+	t.Helper()
+	fixture := filepath.Join("testdata", "zz_fixture_"+t.Name()+".yaml")
+	if os.Getenv("UPDATE") != "" {
+		err := os.WriteFile(fixture, output, 0644)
+		assert.Nil(t, err)
+	}
+	expected, err := os.ReadFile(fixture)
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(output), "re-run the test with UPDATE=true to update %s", fixture)
+}
+
+func TestBundleConfig(t *testing.T) {
+	cfg, err := LoadBundleConfig(filepath.Join("testdata", "config.yaml"))
+	assert.Nil(t, err)
+
+	manifests, err := cfg.FilterManifests(bundleManifests())
+	assert.Nil(t, err)
+
+	var kept []string
+	for _, manifest := range manifests {
+		kept = append(kept, ManifestRef{Kind: manifest.GetKind(), Name: manifest.GetName()}.String())
+	}
+	output, err := yaml.Marshal(map[string]interface{}{
+		"chartName":        cfg.ChartName,
+		"chartDescription": cfg.ChartDescription,
+		"manifests":        kept,
+	})
+	assert.Nil(t, err)
+	compareWithFixture(t, output)
+}
+
+func TestLoadBundleConfigDefaults(t *testing.T) {
+	cfg, err := LoadBundleConfig("")
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultBundleConfig(), cfg)
+}
+
+func TestLoadBundleConfigUnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte("chartNmae: mce\n"), 0644)
+	assert.Nil(t, err)
+
+	_, err = LoadBundleConfig(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "chartNmae")
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         BundleConfig
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			cfg: BundleConfig{
+				ChartName:        "mce",
+				ChartDescription: "description",
+				ExcludeManifests: []ManifestRef{{Kind: "Service", Name: "ocm-webhook"}},
+			},
+		},
+		{
+			name: "invalid chart name",
+			cfg: BundleConfig{
+				ChartName:        "MCE",
+				ChartDescription: "description",
+			},
+			expectedErr: "chartName",
+		},
+		{
+			name: "empty description",
+			cfg: BundleConfig{
+				ChartName: "mce",
+			},
+			expectedErr: "chartDescription",
+		},
+		{
+			name: "incomplete exclusion",
+			cfg: BundleConfig{
+				ChartName:        "mce",
+				ChartDescription: "description",
+				ExcludeManifests: []ManifestRef{{Kind: "Service"}},
+			},
+			expectedErr: "excludeManifests[0] must set kind and name",
+		},
+		{
+			name: "duplicate exclusion",
+			cfg: BundleConfig{
+				ChartName:        "mce",
+				ChartDescription: "description",
+				ExcludeManifests: []ManifestRef{
+					{Kind: "Service", Name: "ocm-webhook"},
+					{Kind: "Service", Name: "ocm-webhook"},
+				},
+			},
+			expectedErr: "listed more than once",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.Validate()
+			if tc.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestFilterManifestsUnmatchedExclusion(t *testing.T) {
+	cfg := DefaultBundleConfig()
+	cfg.ExcludeManifests = []ManifestRef{{Kind: "Service", Name: "renamed"}}
+
+	_, err := cfg.FilterManifests(bundleManifests())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Service/renamed not found in the bundle")
+}
@@ -2,6 +2,7 @@ package customize
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,22 +17,37 @@ var (
 
 type Customizer func(unstructured.Unstructured) (unstructured.Unstructured, map[string]string, error)
 
-var customizerFuncs = []Customizer{
+// namespaceCustomizerFuncs place manifests in the Helm release namespace.
+var namespaceCustomizerFuncs = []Customizer{
 	parameterizeNamespace,
 	parameterizeRoleBindingSubjectsNamespace,
 	parameterizeClusterRoleBindingSubjectsNamespace,
+}
+
+var customizerFuncs = []Customizer{
 	parameterizeOperandsImageRegistries,
 	parameterizeDeployment,
 	annotationCleaner,
 }
 
-func CustomizeManifests(objects []unstructured.Unstructured) ([]unstructured.Unstructured, map[string]interface{}, error) {
+// CustomizeManifests applies all customizations to the manifests. If namespace
+// is set, namespaced manifests are placed in that namespace instead of the
+// Helm release namespace.
+func CustomizeManifests(objects []unstructured.Unstructured, namespace string) ([]unstructured.Unstructured, map[string]interface{}, error) {
+	namespaceCustomizers := namespaceCustomizerFuncs
+	if namespace != "" {
+		namespaceCustomizers = []Customizer{
+			overrideNamespace(namespace, installNamespace(objects), clusterScopeResolver(objects)),
+		}
+	}
+	customizers := append(slices.Clone(namespaceCustomizers), customizerFuncs...)
+
 	parameters := make(map[string]string)
 	customizedManifests := make([]unstructured.Unstructured, len(objects))
 	for i, obj := range objects {
 		var err error
 		var newParams map[string]string
-		for _, customerFunc := range customizerFuncs {
+		for _, customerFunc := range customizers {
 			obj, newParams, err = customerFunc(obj)
 			if err != nil {
 				return nil, nil, fmt.Errorf("failed to apply customer function: %v", err)
@@ -89,6 +105,46 @@ func parameterizeClusterRoleBindingSubjectsNamespace(obj unstructured.Unstructur
 	return obj, nil, nil
 }
 
+// overrideNamespace sets the namespace of all namespaced manifests, including
+// those lacking one, and of role binding ServiceAccount subjects in the
+// bundle's install namespace. Subjects in other namespaces refer to service
+// accounts this chart does not create, so they are left untouched, as are
+// cluster scoped manifests.
+func overrideNamespace(namespace, installNamespace string, isClusterScoped func(unstructured.Unstructured) bool) Customizer {
+	return func(obj unstructured.Unstructured) (unstructured.Unstructured, map[string]string, error) {
+		if isClusterScoped(obj) {
+			if isClusterRoleBinding(obj) {
+				return setServiceAccountSubjectsNamespace(obj, installNamespace, namespace)
+			}
+			return obj, nil, nil
+		}
+		obj.SetNamespace(namespace)
+		if isRoleBinding(obj) {
+			return setServiceAccountSubjectsNamespace(obj, installNamespace, namespace)
+		}
+		return obj, nil, nil
+	}
+}
+
+// setServiceAccountSubjectsNamespace moves the ServiceAccount subjects of a
+// role binding from one namespace to another.
+func setServiceAccountSubjectsNamespace(obj unstructured.Unstructured, from, to string) (unstructured.Unstructured, map[string]string, error) {
+	subjects, found, err := unstructured.NestedSlice(obj.Object, "subjects")
+	if err != nil {
+		return unstructured.Unstructured{}, nil, fmt.Errorf("failed to read subjects of %s %s: %v", obj.GetKind(), obj.GetName(), err)
+	}
+	if !found {
+		return obj, nil, nil
+	}
+	for _, s := range subjects {
+		if subject, ok := s.(map[string]interface{}); ok && subject["kind"] == "ServiceAccount" && subject["namespace"] == from {
+			subject["namespace"] = to
+		}
+	}
+	err = unstructured.SetNestedSlice(obj.Object, subjects, "subjects")
+	return obj, nil, err
+}
+
 func parameterizeOperandsImageRegistries(obj unstructured.Unstructured) (unstructured.Unstructured, map[string]string, error) {
 	if isOperatorDeployment(obj) {
 		deployment := &appsv1.Deployment{}
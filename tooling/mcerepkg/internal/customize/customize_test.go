@@ -247,3 +247,82 @@ func TestAnnotationCleaner(t *testing.T) {
 		})
 	}
 }
+
+func TestCustomizeManifestsNamespaceOverride(t *testing.T) {
+	deployment, err := convertToUnstructured(buildMulticlusterEngineDeployment())
+	assert.Nil(t, err)
+	deployment.SetNamespace("test-namespace")
+
+	roleBinding, err := convertToUnstructured(&rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RoleBinding",
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-rolebinding",
+			Namespace: "test-namespace",
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      "test-sa",
+				Namespace: "test-namespace",
+			},
+			{
+				Kind: "User",
+				Name: "test-user",
+			},
+			{
+				Kind:      "ServiceAccount",
+				Name:      "monitoring-sa",
+				Namespace: "openshift-monitoring",
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	crd := unstructured.Unstructured{}
+	crd.SetAPIVersion("apiextensions.k8s.io/v1")
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("multiclusterengines.multicluster.openshift.io")
+	assert.Nil(t, unstructured.SetNestedField(crd.Object, "multicluster.openshift.io", "spec", "group"))
+	assert.Nil(t, unstructured.SetNestedField(crd.Object, "MultiClusterEngine", "spec", "names", "kind"))
+	assert.Nil(t, unstructured.SetNestedField(crd.Object, "Cluster", "spec", "scope"))
+
+	mce := unstructured.Unstructured{}
+	mce.SetAPIVersion("multicluster.openshift.io/v1")
+	mce.SetKind("MultiClusterEngine")
+	mce.SetName("multiclusterengine")
+
+	configMap := unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetName("test-configmap")
+
+	customized, _, err := CustomizeManifests([]unstructured.Unstructured{deployment, roleBinding, crd, mce, configMap}, "override")
+	assert.Nil(t, err)
+	assert.Len(t, customized, 5)
+
+	assert.Equal(t, "override", customized[0].GetNamespace())
+	assert.Equal(t, "override", customized[1].GetNamespace())
+	assert.Equal(t, "", customized[2].GetNamespace())
+	assert.Equal(t, "", customized[3].GetNamespace())
+	assert.Equal(t, "override", customized[4].GetNamespace())
+
+	modifiedRb := &rbacv1.RoleBinding{}
+	err = convertFromUnstructured(customized[1], modifiedRb)
+	assert.Nil(t, err)
+	assert.Equal(t, "override", modifiedRb.Subjects[0].Namespace)
+	assert.Equal(t, "", modifiedRb.Subjects[1].Namespace)
+	assert.Equal(t, "openshift-monitoring", modifiedRb.Subjects[2].Namespace)
+}
+
+func TestCustomizeManifestsReleaseNamespace(t *testing.T) {
+	deployment, err := convertToUnstructured(buildDeployment("some-deployment", "registry.io/some-image:latest", nil))
+	assert.Nil(t, err)
+	deployment.SetNamespace("test-namespace")
+
+	customized, _, err := CustomizeManifests([]unstructured.Unstructured{deployment}, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "{{ .Release.Namespace }}", customized[0].GetNamespace())
+}
@@ -0,0 +1,72 @@
+package customize
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ImageResolver resolves an image reference, returning an error if the
+// image manifest can't be found
+type ImageResolver func(imageRef string) error
+
+// ImageReferences returns the distinct image references of the deployments,
+// including the operand images passed to the operator via env vars. Collect
+// them before customization, which parameterizes the image registries.
+func ImageReferences(objects []unstructured.Unstructured) ([]string, error) {
+	var images []string
+	for _, obj := range objects {
+		if !isDeployment(obj) {
+			continue
+		}
+		deployment, err := deploymentFromUnstructured(obj)
+		if err != nil {
+			return nil, fmt.Errorf("deployment %s is invalid: %v", obj.GetName(), err)
+		}
+		podSpec := deployment.Spec.Template.Spec
+		for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+			images = append(images, container.Image)
+			for _, env := range container.Env {
+				if isOperandImageEnvVar(env.Name) {
+					images = append(images, env.Value)
+				}
+			}
+		}
+	}
+	images = slices.DeleteFunc(images, func(image string) bool { return image == "" })
+	slices.Sort(images)
+	return slices.Compact(images), nil
+}
+
+// VerifyImages resolves every image reference and fails listing the ones
+// that can't be resolved. Images whose registry can't be reached are skipped
+// if allowUnreachable is set.
+func VerifyImages(images []string, resolve ImageResolver, allowUnreachable bool) error {
+	var unresolvable []string
+	for _, image := range images {
+		err := resolve(image)
+		if err == nil {
+			continue
+		}
+		if allowUnreachable && isUnreachable(err) {
+			continue
+		}
+		unresolvable = append(unresolvable, fmt.Sprintf("%s: %v", image, err))
+	}
+	if len(unresolvable) > 0 {
+		return fmt.Errorf("failed to resolve %d images:\n%s", len(unresolvable), strings.Join(unresolvable, "\n"))
+	}
+	return nil
+}
+
+// isUnreachable returns true if err shows the registry could not be
+// contacted at all, as opposed to the registry not knowing the image
+func isUnreachable(err error) bool {
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	return errors.As(err, &opErr) || errors.As(err, &dnsErr)
+}
@@ -0,0 +1,83 @@
+package customize
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestImageReferences(t *testing.T) {
+	operator, err := convertToUnstructured(buildMulticlusterEngineDeployment())
+	assert.Nil(t, err)
+	other, err := convertToUnstructured(buildDeployment("some-deployment", "registry.io/test-image:abcdef", nil))
+	assert.Nil(t, err)
+
+	images, err := ImageReferences([]unstructured.Unstructured{operator, other})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"registry.io/operand-image-1:abcdef",
+		"registry.io/operand-image-2:abcdef",
+		"registry.io/test-image:abcdef",
+	}, images)
+}
+
+func TestVerifyImages(t *testing.T) {
+	unreachable := &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("connection refused")}
+	resolve := func(imageRef string) error {
+		switch imageRef {
+		case "registry.io/missing:abcdef":
+			return fmt.Errorf("MANIFEST_UNKNOWN")
+		case "unreachable.io/image:abcdef":
+			return fmt.Errorf("Get \"https://unreachable.io/v2/\": %w", unreachable)
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name             string
+		images           []string
+		allowUnreachable bool
+		expectedErr      string
+	}{
+		{
+			name:   "all images resolve",
+			images: []string{"registry.io/test-image:abcdef"},
+		},
+		{
+			name:        "missing image",
+			images:      []string{"registry.io/test-image:abcdef", "registry.io/missing:abcdef"},
+			expectedErr: "registry.io/missing:abcdef",
+		},
+		{
+			name:        "unreachable registry",
+			images:      []string{"unreachable.io/image:abcdef"},
+			expectedErr: "unreachable.io/image:abcdef",
+		},
+		{
+			name:             "unreachable registry allowed",
+			images:           []string{"unreachable.io/image:abcdef"},
+			allowUnreachable: true,
+		},
+		{
+			name:             "missing image with unreachable allowed",
+			images:           []string{"registry.io/missing:abcdef"},
+			allowUnreachable: true,
+			expectedErr:      "registry.io/missing:abcdef",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := VerifyImages(tc.images, resolve, tc.allowUnreachable)
+			if tc.expectedErr == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package customize
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const notesFileName = "NOTES.txt"
+
+// BuildNotes generates the content of a chart's templates/NOTES.txt from the
+// display name, version and description of the bundle's CSV, followed by the
+// names of the CRDs installed by the chart.
+func BuildNotes(displayName, version, description string, objects []unstructured.Unstructured) []byte {
+	var notes bytes.Buffer
+
+	fmt.Fprintf(&notes, "%s %s has been installed in namespace {{ .Release.Namespace }}.\n", escapeTemplate(displayName), escapeTemplate(version))
+
+	if description = strings.TrimSpace(description); description != "" {
+		fmt.Fprintf(&notes, "\n%s\n", escapeTemplate(description))
+	}
+
+	var crdNames []string
+	for _, obj := range objects {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			crdNames = append(crdNames, obj.GetName())
+		}
+	}
+	if len(crdNames) > 0 {
+		sort.Strings(crdNames)
+		notes.WriteString("\nInstalled CustomResourceDefinitions:\n")
+		for _, name := range crdNames {
+			fmt.Fprintf(&notes, "  - %s\n", name)
+		}
+	}
+
+	return notes.Bytes()
+}
+
+// LoadScaffoldNotes returns the content of a NOTES.txt file in the scaffold
+// directory, or nil if there is none.
+func LoadScaffoldNotes(scaffoldDir string) ([]byte, error) {
+	if scaffoldDir == "" {
+		return nil, nil
+	}
+	notes, err := os.ReadFile(filepath.Join(scaffoldDir, notesFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return notes, err
+}
+
+// escapeTemplate keeps text taken from the bundle from being interpreted
+// as Helm template actions when NOTES.txt is rendered.
+func escapeTemplate(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{ "{{" }}`)
+}
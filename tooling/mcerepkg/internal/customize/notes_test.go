@@ -0,0 +1,50 @@
+package customize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuildNotes(t *testing.T) {
+	crd := unstructured.Unstructured{}
+	crd.SetKind("CustomResourceDefinition")
+	crd.SetName("multiclusterengines.multicluster.openshift.io")
+	deployment := unstructured.Unstructured{}
+	deployment.SetKind("Deployment")
+	deployment.SetName("multicluster-engine-operator")
+
+	notes := BuildNotes("multicluster engine for Kubernetes", "2.7.0", "Manages {{ clusters }}.\n", []unstructured.Unstructured{deployment, crd})
+
+	expected := `multicluster engine for Kubernetes 2.7.0 has been installed in namespace {{ .Release.Namespace }}.
+
+Manages {{ "{{" }} clusters }}.
+
+Installed CustomResourceDefinitions:
+  - multiclusterengines.multicluster.openshift.io
+`
+	assert.Equal(t, expected, string(notes))
+}
+
+func TestBuildNotesWithoutDescription(t *testing.T) {
+	notes := BuildNotes("operator", "1.0.0", "", nil)
+	assert.Equal(t, "operator 1.0.0 has been installed in namespace {{ .Release.Namespace }}.\n", string(notes))
+}
+
+func TestLoadScaffoldNotes(t *testing.T) {
+	scaffoldDir := t.TempDir()
+
+	notes, err := LoadScaffoldNotes(scaffoldDir)
+	assert.Nil(t, err)
+	assert.Nil(t, notes)
+
+	err = os.WriteFile(filepath.Join(scaffoldDir, "NOTES.txt"), []byte("custom notes"), 0644)
+	assert.Nil(t, err)
+
+	notes, err = LoadScaffoldNotes(scaffoldDir)
+	assert.Nil(t, err)
+	assert.Equal(t, "custom notes", string(notes))
+}
@@ -2,12 +2,15 @@ package customize
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func convertFromUnstructured(from unstructured.Unstructured, to interface{}) error {
@@ -57,9 +60,60 @@ var (
 	deploymentGVK             = appsv1.SchemeGroupVersion.WithKind("Deployment")
 	roleBindingGVK            = rbacv1.SchemeGroupVersion.WithKind("RoleBinding")
 	clusterRoleBindingGVK     = rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding")
+	crdGK                     = apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
 	mceOperatorDeploymentName = "multicluster-engine-operator"
 )
 
+// clusterScopedKinds are the kinds of built-in cluster scoped resources
+// found in OLM bundles. The scope of custom resources comes from their
+// CustomResourceDefinitions.
+var clusterScopedKinds = []string{
+	"APIService",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"ConsolePlugin",
+	"CustomResourceDefinition",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"PriorityClass",
+	"StorageClass",
+	"ValidatingWebhookConfiguration",
+}
+
+// clusterScopeResolver returns a function reporting whether a manifest is
+// cluster scoped, using the scope declared by any CustomResourceDefinition
+// among objects for its group and kind, or else the built-in kinds.
+func clusterScopeResolver(objects []unstructured.Unstructured) func(unstructured.Unstructured) bool {
+	crdScopes := make(map[schema.GroupKind]bool)
+	for _, obj := range objects {
+		if obj.GroupVersionKind().GroupKind() != crdGK {
+			continue
+		}
+		group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+		scope, _, _ := unstructured.NestedString(obj.Object, "spec", "scope")
+		crdScopes[schema.GroupKind{Group: group, Kind: kind}] = scope == string(apiextensionsv1.ClusterScoped)
+	}
+
+	return func(obj unstructured.Unstructured) bool {
+		if clusterScoped, ok := crdScopes[obj.GroupVersionKind().GroupKind()]; ok {
+			return clusterScoped
+		}
+		return slices.Contains(clusterScopedKinds, obj.GetKind())
+	}
+}
+
+// installNamespace returns the namespace the bundle was rendered into,
+// taken from the operator deployment, or "" if there is none.
+func installNamespace(objects []unstructured.Unstructured) string {
+	for _, obj := range objects {
+		if isOperatorDeployment(obj) {
+			return obj.GetNamespace()
+		}
+	}
+	return ""
+}
+
 func isDeployment(obj unstructured.Unstructured) bool {
 	return obj.GroupVersionKind() == deploymentGVK
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Azure/ARO-HCP/tooling/mcerepkg/internal/config"
 	"github.com/Azure/ARO-HCP/tooling/mcerepkg/internal/customize"
 	"github.com/Azure/ARO-HCP/tooling/mcerepkg/internal/olm"
 
@@ -24,7 +26,8 @@ var (
 		Long:  "mce-repkg",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return buildChart(
-				outputDir, mceBundle, sourceLink, scaffoldDir,
+				outputDir, mceBundle, sourceLink, scaffoldDir, namespace,
+				verifyImages, allowUnreachable, configFile,
 			)
 		},
 	}
@@ -32,6 +35,11 @@ var (
 	outputDir   string
 	scaffoldDir string
 	sourceLink  string
+	namespace   string
+	configFile  string
+
+	verifyImages     bool
+	allowUnreachable bool
 )
 
 func main() {
@@ -39,6 +47,10 @@ func main() {
 	cmd.Flags().StringVarP(&scaffoldDir, "scaffold-dir", "s", "", "Directory containing additional templates to be added to the generated Helm Chart")
 	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", "", "Output directory for the generated Helm Chart")
 	cmd.Flags().StringVarP(&sourceLink, "source-link", "l", "", "Link to the Bundle image that is repackaged")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace for all namespaced manifests, instead of the Helm release namespace")
+	cmd.Flags().StringVarP(&configFile, "config", "c", "", "YAML config file with chart metadata overrides and manifests to exclude")
+	cmd.Flags().BoolVar(&verifyImages, "verify-images", false, "Fail if any image referenced by the manifests can't be resolved in its registry")
+	cmd.Flags().BoolVar(&allowUnreachable, "allow-unreachable", false, "Skip verification of images whose registry can't be reached, used with --verify-images")
 	err := cmd.MarkFlagRequired("mce-bundle")
 	if err != nil {
 		log.Fatalf("failed to mark flag as required: %v", err)
@@ -54,9 +66,15 @@ func main() {
 	}
 }
 
-func buildChart(outputDir, mceOlmBundle, sourceLink, scaffoldDir string) error {
+func buildChart(outputDir, mceOlmBundle, sourceLink, scaffoldDir, namespace string, verifyImages, allowUnreachable bool, configFile string) error {
 	ctx := context.Background()
 
+	// load config before doing any work, so that mistakes fail fast
+	cfg, err := config.LoadBundleConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+
 	// load OLM bundle manifests
 	img, err := crane.Load(mceOlmBundle)
 	if err != nil {
@@ -67,6 +85,12 @@ func buildChart(outputDir, mceOlmBundle, sourceLink, scaffoldDir string) error {
 		return fmt.Errorf("failed to extract OLM bundle image: %v", err)
 	}
 
+	// drop excluded manifests
+	olmManifests, err = cfg.FilterManifests(olmManifests)
+	if err != nil {
+		return fmt.Errorf("failed to exclude manifests: %v", err)
+	}
+
 	// sanity check manifests
 	err = customize.SanityCheck(olmManifests)
 	if err != nil {
@@ -80,17 +104,38 @@ func buildChart(outputDir, mceOlmBundle, sourceLink, scaffoldDir string) error {
 	}
 
 	// customize manifests
-	customizedManifests, values, err := customize.CustomizeManifests(append(olmManifests, scaffoldManifests...))
+	customizedManifests, values, err := customize.CustomizeManifests(append(olmManifests, scaffoldManifests...), namespace)
 	if err != nil {
 		return fmt.Errorf("failed to customize manifests: %v", err)
 	}
 
+	// verify images, using the references from before customization since
+	// the customized manifests carry parameterized image registries
+	if verifyImages {
+		images, err := customize.ImageReferences(olmManifests)
+		if err != nil {
+			return fmt.Errorf("failed to collect image references: %v", err)
+		}
+		for _, relatedImage := range reg.CSV.Spec.RelatedImages {
+			images = append(images, relatedImage.Image)
+		}
+		slices.Sort(images)
+		resolve := func(imageRef string) error {
+			_, err := crane.Head(imageRef, crane.WithContext(ctx))
+			return err
+		}
+		err = customize.VerifyImages(slices.Compact(images), resolve, allowUnreachable)
+		if err != nil {
+			return fmt.Errorf("failed to verify images: %v", err)
+		}
+	}
+
 	// build chart
 	mceChart := &chart.Chart{
 		Metadata: &chart.Metadata{
 			APIVersion:  "v2",
-			Name:        "multicluster-engine",
-			Description: "A Helm chart for multicluster-engine",
+			Name:        cfg.ChartName,
+			Description: cfg.ChartDescription,
 			Version:     reg.CSV.Spec.Version.String(),
 			AppVersion:  reg.CSV.Spec.Version.String(),
 			Type:        "application",
@@ -128,6 +173,19 @@ func buildChart(outputDir, mceOlmBundle, sourceLink, scaffoldDir string) error {
 			Data: yamlData,
 		})
 	}
+
+	// add NOTES.txt, preferring one provided by the scaffold
+	notes, err := customize.LoadScaffoldNotes(scaffoldDir)
+	if err != nil {
+		return fmt.Errorf("failed to load scaffold NOTES.txt: %v", err)
+	}
+	if notes == nil {
+		notes = customize.BuildNotes(reg.CSV.Spec.DisplayName, reg.CSV.Spec.Version.String(), reg.CSV.Spec.Description, customizedManifests)
+	}
+	chartFiles = append(chartFiles, &chart.File{
+		Name: "templates/NOTES.txt",
+		Data: notes,
+	})
 	mceChart.Templates = chartFiles
 
 	// store chart
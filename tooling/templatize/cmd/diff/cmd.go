@@ -0,0 +1,43 @@
+package diff
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() (*cobra.Command, error) {
+	opts := DefaultDiffOptions()
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "diff rendered templates against previously rendered files",
+		Long:  "diff renders templates like generate, but prints a unified diff against previously rendered files instead of writing them. It fails when differences exist.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return diff(cmd.Context(), opts)
+		},
+	}
+	if err := BindDiffOptions(opts, cmd); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func diff(ctx context.Context, opts *RawDiffOptions) error {
+	validated, err := opts.Validate()
+	if err != nil {
+		return err
+	}
+	completed, err := validated.Complete()
+	if err != nil {
+		return err
+	}
+	differs, err := completed.Diff()
+	if err != nil {
+		return err
+	}
+	if differs {
+		return fmt.Errorf("rendered output differs from %s", opts.Target)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+
+	options "github.com/Azure/ARO-HCP/tooling/templatize/cmd"
+	"github.com/Azure/ARO-HCP/tooling/templatize/pkg/config"
+)
+
+func DefaultDiffOptions() *RawDiffOptions {
+	return &RawDiffOptions{
+		RolloutOptions: options.DefaultRolloutOptions(),
+	}
+}
+
+func BindDiffOptions(opts *RawDiffOptions, cmd *cobra.Command) error {
+	err := options.BindRolloutOptions(opts.RolloutOptions, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to bind raw options: %w", err)
+	}
+	cmd.Flags().StringVar(&opts.Input, "input", opts.Input, "input file or directory path")
+	cmd.Flags().StringVar(&opts.Target, "target", opts.Target, "previously rendered file or directory path to compare against")
+
+	for _, flag := range []string{"config-file", "input", "target"} {
+		if err := cmd.MarkFlagFilename(flag); err != nil {
+			return fmt.Errorf("failed to mark flag %q as a file: %w", flag, err)
+		}
+	}
+	return nil
+}
+
+// RawDiffOptions holds input values.
+type RawDiffOptions struct {
+	RolloutOptions *options.RawRolloutOptions
+	Input          string
+	Target         string
+}
+
+// validatedDiffOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
+type validatedDiffOptions struct {
+	*RawDiffOptions
+	*options.ValidatedRolloutOptions
+}
+
+type ValidatedDiffOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*validatedDiffOptions
+}
+
+// diffFile pairs a template with the previously rendered file it is compared against.
+type diffFile struct {
+	InputFile  string
+	TargetFile string
+	// TargetName is the path of the target file shown in the diff header.
+	TargetName string
+}
+
+// completedDiffOptions is a private wrapper that enforces a call of Complete() before a diff can be invoked.
+type completedDiffOptions struct {
+	*options.RolloutOptions
+	InputFS  fs.FS
+	TargetFS fs.FS
+	Files    []diffFile
+	Output   io.Writer
+}
+
+type DiffOptions struct {
+	// Embed a private pointer that cannot be instantiated outside of this package.
+	*completedDiffOptions
+}
+
+func (o *RawDiffOptions) Validate() (*ValidatedDiffOptions, error) {
+	validatedRolloutOptions, err := o.RolloutOptions.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("validation failed for raw options: %w", err)
+	}
+
+	if _, err := os.Stat(o.Input); os.IsNotExist(err) {
+		return nil, fmt.Errorf("input file %s does not exist", o.Input)
+	}
+
+	if o.Target == "" {
+		return nil, fmt.Errorf("target must be set")
+	}
+
+	return &ValidatedDiffOptions{
+		validatedDiffOptions: &validatedDiffOptions{
+			RawDiffOptions:          o,
+			ValidatedRolloutOptions: validatedRolloutOptions,
+		},
+	}, nil
+}
+
+func (o *ValidatedDiffOptions) Complete() (*DiffOptions, error) {
+	completed, err := o.ValidatedRolloutOptions.Complete()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(o.Input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat input %s: %w", o.Input, err)
+	}
+
+	var inputFS, targetFS fs.FS
+	var files []diffFile
+	if info.IsDir() {
+		// Every file in the input directory is compared against
+		// the file at the same relative path in the target directory.
+		inputFS = os.DirFS(o.Input)
+		targetFS = os.DirFS(o.Target)
+		err = fs.WalkDir(inputFS, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			files = append(files, diffFile{
+				InputFile:  path,
+				TargetFile: path,
+				TargetName: filepath.Join(o.Target, path),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk input directory %s: %w", o.Input, err)
+		}
+	} else {
+		inputFS = os.DirFS(filepath.Dir(o.Input))
+		targetFS = os.DirFS(filepath.Dir(o.Target))
+		files = []diffFile{{
+			InputFile:  filepath.Base(o.Input),
+			TargetFile: filepath.Base(o.Target),
+			TargetName: o.Target,
+		}}
+	}
+
+	return &DiffOptions{
+		completedDiffOptions: &completedDiffOptions{
+			RolloutOptions: completed,
+			InputFS:        inputFS,
+			TargetFS:       targetFS,
+			Files:          files,
+			Output:         os.Stdout,
+		},
+	}, nil
+}
+
+// Diff renders every input template and writes a unified diff against the
+// corresponding target file for each one that differs. A missing target file
+// is treated as empty. It reports whether any differences were found.
+func (opts *DiffOptions) Diff() (bool, error) {
+	differs := false
+	for _, file := range opts.Files {
+		content, err := fs.ReadFile(opts.InputFS, file.InputFile)
+		if err != nil {
+			return false, err
+		}
+		rendered, err := config.PreprocessContent(content, opts.RolloutOptions.Config)
+		if err != nil {
+			return false, fmt.Errorf("failed to render %s: %w", file.InputFile, err)
+		}
+
+		existing, err := fs.ReadFile(opts.TargetFS, file.TargetFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(string(existing)),
+			B:        difflib.SplitLines(string(rendered)),
+			FromFile: file.TargetName,
+			ToFile:   file.TargetName + " (rendered)",
+			Context:  3,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to diff %s: %w", file.TargetName, err)
+		}
+		if diff == "" {
+			continue
+		}
+
+		differs = true
+		if _, err := io.WriteString(opts.Output, diff); err != nil {
+			return false, err
+		}
+	}
+	return differs, nil
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+
+	options "github.com/Azure/ARO-HCP/tooling/templatize/cmd"
+	"github.com/Azure/ARO-HCP/tooling/templatize/pkg/config"
+)
+
+func TestDiff(t *testing.T) {
+	vars := config.Variables{
+		"region_maestro_keyvault": "kv",
+	}
+	input := "param maestroKeyVaultName = '{{ .region_maestro_keyvault }}'\nparam maestroEventGridMaxClientSessionsPerAuthName = 4\n"
+
+	for _, testCase := range []struct {
+		name   string
+		target fstest.MapFS
+
+		expectedDiffers bool
+		expectedOutput  string
+	}{
+		{
+			name: "unchanged output produces no diff",
+			target: fstest.MapFS{"test.bicepparam": &fstest.MapFile{
+				Data: []byte("param maestroKeyVaultName = 'kv'\nparam maestroEventGridMaxClientSessionsPerAuthName = 4\n"),
+			}},
+		},
+		{
+			name: "changed output produces a diff",
+			target: fstest.MapFS{"test.bicepparam": &fstest.MapFile{
+				Data: []byte("param maestroKeyVaultName = 'old'\nparam maestroEventGridMaxClientSessionsPerAuthName = 4\n"),
+			}},
+			expectedDiffers: true,
+			expectedOutput: `--- out/test.bicepparam
++++ out/test.bicepparam (rendered)
+@@ -1,2 +1,2 @@
+-param maestroKeyVaultName = 'old'
++param maestroKeyVaultName = 'kv'
+ param maestroEventGridMaxClientSessionsPerAuthName = 4
+`,
+		},
+		{
+			name:            "missing target is diffed as empty",
+			target:          fstest.MapFS{},
+			expectedDiffers: true,
+			expectedOutput: `--- out/test.bicepparam
++++ out/test.bicepparam (rendered)
+@@ -0,0 +1,2 @@
++param maestroKeyVaultName = 'kv'
++param maestroEventGridMaxClientSessionsPerAuthName = 4
+`,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			opts := DiffOptions{
+				completedDiffOptions: &completedDiffOptions{
+					RolloutOptions: options.NewRolloutOptions(vars),
+					InputFS:        fstest.MapFS{"test.bicepparam.tmpl": &fstest.MapFile{Data: []byte(input)}},
+					TargetFS:       testCase.target,
+					Files: []diffFile{{
+						InputFile:  "test.bicepparam.tmpl",
+						TargetFile: "test.bicepparam",
+						TargetName: "out/test.bicepparam",
+					}},
+					Output: output,
+				},
+			}
+			differs, err := opts.Diff()
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedDiffers, differs)
+			if diff := cmp.Diff(testCase.expectedOutput, output.String()); diff != "" {
+				t.Errorf("unexpected output (-want, +got): %s", diff)
+			}
+		})
+	}
+}
@@ -20,7 +20,7 @@ func TestExecuteTemplate(t *testing.T) {
 		input string
 
 		expected      string
-		expectedError bool
+		expectedError []string
 	}{
 		{
 			name: "happy case generates a file",
@@ -43,7 +43,10 @@ param maestroEventGridMaxClientSessionsPerAuthName = 4`,
 			input: `param maestroKeyVaultName = '{{ .region_maestro_keyvault }}'
 param maestroEventGridNamespacesName = '{{ .region_eventgrid_namespace }}'
 param maestroEventGridMaxClientSessionsPerAuthName = 4`,
-			expectedError: true,
+			expectedError: []string{
+				"failed to preprocess file test",
+				`map has no entry for key "region_eventgrid_namespace"`,
+			},
 		},
 	} {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -57,8 +60,10 @@ param maestroEventGridMaxClientSessionsPerAuthName = 4`,
 				},
 			}
 			err := opts.ExecuteTemplate()
-			if testCase.expectedError {
-				assert.Error(t, err)
+			if testCase.expectedError != nil {
+				for _, expected := range testCase.expectedError {
+					assert.ErrorContains(t, err, expected)
+				}
 				return
 			}
 			assert.NoError(t, err)
@@ -116,5 +116,8 @@ func (opts *GenerationOptions) ExecuteTemplate() error {
 	if err != nil {
 		return err
 	}
-	return config.PreprocessContentIntoWriter(content, opts.RolloutOptions.Config, opts.OutputFile)
+	if err := config.PreprocessContentIntoWriter(content, opts.RolloutOptions.Config, opts.OutputFile); err != nil {
+		return fmt.Errorf("failed to preprocess file %s: %w", opts.InputFile, err)
+	}
+	return nil
 }
@@ -14,11 +14,15 @@ func NewCommand() (*cobra.Command, error) {
 	opts := options.DefaultRolloutOptions()
 
 	format := "json"
+	uses := ""
 	cmd := &cobra.Command{
 		Use:   "inspect",
 		Short: "inspect",
 		Long:  "inspect",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if uses != "" {
+				return dumpUsages(cmd.Context(), uses, opts)
+			}
 			return dumpConfig(cmd.Context(), format, opts)
 		},
 	}
@@ -26,6 +30,7 @@ func NewCommand() (*cobra.Command, error) {
 		return nil, err
 	}
 	cmd.Flags().StringVar(&format, "format", format, "output format (json, yaml)")
+	cmd.Flags().StringVar(&uses, "uses", uses, "list the config paths whose template references the given variable (e.g. ctx.region)")
 	return cmd, nil
 }
 
@@ -55,3 +60,23 @@ func dumpConfig(ctx context.Context, format string, opts *options.RawRolloutOpti
 	fmt.Println(data)
 	return nil
 }
+
+func dumpUsages(ctx context.Context, variable string, opts *options.RawRolloutOptions) error {
+	validated, err := opts.Validate()
+	if err != nil {
+		return err
+	}
+	completed, err := validated.ValidatedOptions.Complete()
+	if err != nil {
+		return err
+	}
+
+	paths, err := completed.ConfigProvider.GetVariableUsages(opts.BaseOptions.Cloud, opts.BaseOptions.DeployEnv, opts.Region, variable)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		fmt.Println(path)
+	}
+	return nil
+}
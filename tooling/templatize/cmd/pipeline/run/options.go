@@ -3,6 +3,7 @@ package run
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	"github.com/spf13/cobra"
 
@@ -24,6 +25,7 @@ func BindOptions(opts *RawRunOptions, cmd *cobra.Command) error {
 	}
 	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", opts.DryRun, "validate the pipeline without executing it")
 	cmd.Flags().BoolVar(&opts.NoPersist, "no-persist-tag", opts.NoPersist, "toggle if persist tag should not be set")
+	cmd.Flags().StringArrayVar(&opts.OnlySteps, "only-step", opts.OnlySteps, "run only the named step, can be repeated to run multiple steps")
 	return nil
 }
 
@@ -31,6 +33,7 @@ type RawRunOptions struct {
 	PipelineOptions *options.RawPipelineOptions
 	DryRun          bool
 	NoPersist       bool
+	OnlySteps       []string
 }
 
 // validatedRunOptions is a private wrapper that enforces a call of Validate() before Complete() can be invoked.
@@ -49,6 +52,7 @@ type completedRunOptions struct {
 	PipelineOptions *options.PipelineOptions
 	DryRun          bool
 	NoPersist       bool
+	OnlySteps       []string
 }
 
 type RunOptions struct {
@@ -81,6 +85,7 @@ func (o *ValidatedRunOptions) Complete() (*RunOptions, error) {
 			PipelineOptions: completed,
 			DryRun:          o.DryRun,
 			NoPersist:       o.NoPersist,
+			OnlySteps:       o.OnlySteps,
 		},
 	}, nil
 }
@@ -100,11 +105,15 @@ func (o *RunOptions) RunPipeline(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	steps := slices.Clone(o.OnlySteps)
+	if o.PipelineOptions.Step != "" {
+		steps = append(steps, o.PipelineOptions.Step)
+	}
 	return pipeline.RunPipeline(o.PipelineOptions.Pipeline, ctx, &pipeline.PipelineRunOptions{
 		DryRun:                o.DryRun,
 		Vars:                  variables,
 		Region:                rolloutOptions.Region,
-		Step:                  o.PipelineOptions.Step,
+		Steps:                 steps,
 		SubsciptionLookupFunc: pipeline.LookupSubscriptionID,
 		NoPersist:             o.NoPersist,
 	})
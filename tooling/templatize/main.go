@@ -12,6 +12,7 @@ import (
 
 	"github.com/dusted-go/logging/prettylog"
 
+	"github.com/Azure/ARO-HCP/tooling/templatize/cmd/diff"
 	"github.com/Azure/ARO-HCP/tooling/templatize/cmd/generate"
 	"github.com/Azure/ARO-HCP/tooling/templatize/cmd/inspect"
 	"github.com/Azure/ARO-HCP/tooling/templatize/cmd/pipeline"
@@ -45,6 +46,7 @@ func main() {
 
 	commands := []func() (*cobra.Command, error){
 		generate.NewCommand,
+		diff.NewCommand,
 		inspect.NewCommand,
 		pipeline.NewCommand,
 	}
@@ -47,6 +47,7 @@ type ConfigProvider interface {
 	GetDeployEnvVariables(cloud, deployEnv string, configReplacements *ConfigReplacements) (Variables, error)
 	GetRegions(cloud, deployEnv string) ([]string, error)
 	GetRegionOverrides(cloud, deployEnv, region string, configReplacements *ConfigReplacements) (Variables, error)
+	GetVariableUsages(cloud, deployEnv, region, variable string) ([]string, error)
 }
 
 func NewConfigProvider(config string) ConfigProvider {
@@ -236,7 +237,11 @@ func (cp *configProviderImpl) loadConfig(configReplacements *ConfigReplacements)
 	if err != nil {
 		return nil, err
 	}
+	return parseConfig(rawContent)
+}
 
+// parseConfig unmarshals a rendered config file.
+func parseConfig(rawContent []byte) (VariableOverrides, error) {
 	currentVariableOverrides := NewVariableOverrides()
 	if err := yaml.Unmarshal(rawContent, currentVariableOverrides); err == nil {
 		return currentVariableOverrides, nil
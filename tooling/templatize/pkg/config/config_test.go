@@ -1,9 +1,11 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"testing"
+	"text/template"
 
 	"github.com/stretchr/testify/assert"
 
@@ -308,3 +310,102 @@ func TestPreprocessContentMissingKey(t *testing.T) {
 		})
 	}
 }
+
+func TestGetVariableUsages(t *testing.T) {
+	configProvider := NewConfigProvider("../../testdata/config.yaml")
+
+	testCases := []struct {
+		name          string
+		deployEnv     string
+		variable      string
+		expected      []string
+		expectedError bool
+	}{
+		{
+			name:      "region in dev includes the dev override",
+			deployEnv: "dev",
+			variable:  "ctx.region",
+			expected:  []string{"managementClusterSubscription", "region", "regionRG", "serviceClusterSubscription"},
+		},
+		{
+			name:      "region short in dev excludes the overridden value",
+			deployEnv: "dev",
+			variable:  ".ctx.regionShort",
+			expected:  []string{"imageSyncRG", "managementClusterRG", "serviceClusterRG"},
+		},
+		{
+			name:      "region short in int",
+			deployEnv: "int",
+			variable:  "ctx.regionShort",
+			expected:  []string{"imageSyncRG", "managementClusterRG", "regionRG", "serviceClusterRG"},
+		},
+		{
+			name:      "stamp",
+			deployEnv: "int",
+			variable:  "ctx.stamp",
+			expected:  []string{"managementClusterRG"},
+		},
+		{
+			name:          "unknown variable",
+			deployEnv:     "int",
+			variable:      "ctx.unknown",
+			expectedError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, err := configProvider.GetVariableUsages("public", tc.deployEnv, "uksouth", tc.variable)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, paths)
+		})
+	}
+}
+
+func TestMarkVariableUsages(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "field",
+			content:  "a: {{ .ctx.region }}",
+			expected: "a: X",
+		},
+		{
+			name:     "root variable in a pipeline",
+			content:  `a: {{ $.ctx.region | printf "%s-x" }}`,
+			expected: "a: X",
+		},
+		{
+			name:     "parenthesized argument",
+			content:  `a: {{ printf "%s-%s" (.ctx.region) .ctx.stamp }}`,
+			expected: "a: X",
+		},
+		{
+			name:     "inside a conditional",
+			content:  "{{ if .ctx.stamp }}a: {{ .ctx.region }}{{ end }}",
+			expected: "a: X",
+		},
+		{
+			name:     "other variable",
+			content:  "a: {{ .ctx.regionShort }}",
+			expected: "a: short",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := template.New("file").Parse(tc.content)
+			assert.NoError(t, err)
+			markVariableUsages(tmpl.Tree.Root, "ctx.region", "X")
+
+			var rendered bytes.Buffer
+			assert.NoError(t, tmpl.Execute(&rendered, NewConfigReplacements("region", "short", "1").AsMap()))
+			assert.Equal(t, tc.expected, rendered.String())
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// usageMarker returns a placeholder that is substituted for the template
+// actions referencing a variable so that they can be found in the
+// rendered config.
+func usageMarker(variable string) string {
+	return fmt.Sprintf("__templatize_%s__", variable)
+}
+
+// GetVariableUsages returns the sorted dotted paths of all config values
+// rendered by a template action that references the given variable,
+// e.g. "ctx.region", for the given cloud, deploy env and region. Values
+// only guarded by a conditional on the variable are not reported.
+func (cp *configProviderImpl) GetVariableUsages(cloud, deployEnv, region, variable string) ([]string, error) {
+	variable = strings.TrimPrefix(variable, ".")
+
+	knownVariables := variableNames(DefaultConfigReplacements().AsMap(), "")
+	if !slices.Contains(knownVariables, variable) {
+		return nil, fmt.Errorf("unknown variable %s, must be one of %v", variable, knownVariables)
+	}
+
+	err := cp.Validate(cloud, deployEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(cp.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", cp.config, err)
+	}
+	tmpl, err := template.New("file").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	markVariableUsages(tmpl.Tree.Root, variable, usageMarker(variable))
+
+	var rendered bytes.Buffer
+	err = tmpl.Option("missingkey=error").Execute(&rendered, DefaultConfigReplacements().AsMap())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	config, err := parseConfig(rendered.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	// Schema validation is skipped since the markers
+	// are unlikely to satisfy value constraints.
+	variables := Variables{}
+	MergeVariables(variables, config.GetDefaults())
+	MergeVariables(variables, config.GetCloudOverrides(cloud))
+	MergeVariables(variables, config.GetDeployEnvOverrides(cloud, deployEnv))
+	MergeVariables(variables, config.GetRegionOverrides(cloud, deployEnv, region))
+
+	var paths []string
+	collectVariableUsages(variables, "", usageMarker(variable), &paths)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// variableNames returns the sorted dotted names of the leaf values in vars.
+func variableNames(vars map[string]any, prefix string) []string {
+	var names []string
+	for k, v := range vars {
+		if nested, ok := v.(map[string]any); ok {
+			names = append(names, variableNames(nested, joinPath(prefix, k))...)
+		} else {
+			names = append(names, joinPath(prefix, k))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// markVariableUsages replaces every action in list whose pipeline
+// references variable with the marker text.
+func markVariableUsages(list *parse.ListNode, variable, marker string) {
+	if list == nil {
+		return
+	}
+	for i, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.ActionNode:
+			if referencesVariable(n.Pipe, variable) {
+				list.Nodes[i] = &parse.TextNode{NodeType: parse.NodeText, Pos: n.Pos, Text: []byte(marker)}
+			}
+		case *parse.IfNode:
+			markVariableUsages(n.List, variable, marker)
+			markVariableUsages(n.ElseList, variable, marker)
+		case *parse.RangeNode:
+			markVariableUsages(n.List, variable, marker)
+			markVariableUsages(n.ElseList, variable, marker)
+		case *parse.WithNode:
+			markVariableUsages(n.List, variable, marker)
+			markVariableUsages(n.ElseList, variable, marker)
+		case *parse.ListNode:
+			markVariableUsages(n, variable, marker)
+		}
+	}
+}
+
+// referencesVariable returns true if node is, or contains, a reference to
+// the dotted variable, either as a field of dot or of the root variable $.
+func referencesVariable(node parse.Node, variable string) bool {
+	switch n := node.(type) {
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if referencesVariable(cmd, variable) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if referencesVariable(arg, variable) {
+				return true
+			}
+		}
+	case *parse.FieldNode:
+		return strings.Join(n.Ident, ".") == variable
+	case *parse.VariableNode:
+		return len(n.Ident) > 1 && n.Ident[0] == "$" && strings.Join(n.Ident[1:], ".") == variable
+	case *parse.ChainNode:
+		return referencesVariable(n.Node, variable)
+	}
+	return false
+}
+
+func collectVariableUsages(value any, path, marker string, paths *[]string) {
+	if nested, ok := InterfaceToVariables(value); ok {
+		for k, v := range nested {
+			collectVariableUsages(v, joinPath(path, k), marker, paths)
+		}
+		return
+	}
+	switch v := value.(type) {
+	case []any:
+		for i, item := range v {
+			collectVariableUsages(item, joinPath(path, fmt.Sprint(i)), marker, paths)
+		}
+	case string:
+		if strings.Contains(v, marker) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/go-logr/logr"
 
@@ -40,7 +41,7 @@ func NewPipelineFromFile(pipelineFilePath string, vars config.Variables) (*Pipel
 
 type PipelineRunOptions struct {
 	DryRun                bool
-	Step                  string
+	Steps                 []string
 	Region                string
 	Vars                  config.Variables
 	SubsciptionLookupFunc subsciptionLookup
@@ -74,6 +75,11 @@ func (o armOutput) GetValue(key string) (*outPutValue, error) {
 func RunPipeline(pipeline *Pipeline, ctx context.Context, options *PipelineRunOptions) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
+	err := pipeline.validateStepSelection(ctx, options.Steps)
+	if err != nil {
+		return err
+	}
+
 	outPuts := make(map[string]output)
 
 	// set working directory to the pipeline file directory for the
@@ -118,6 +124,36 @@ func RunPipeline(pipeline *Pipeline, ctx context.Context, options *PipelineRunOp
 	return nil
 }
 
+// validateStepSelection ensures all selected steps exist in the pipeline and
+// warns about dependencies of selected steps that are not selected themselves,
+// since those will not run as part of this execution.
+func (p *Pipeline) validateStepSelection(ctx context.Context, steps []string) error {
+	if len(steps) == 0 {
+		return nil
+	}
+	logger := logr.FromContextOrDiscard(ctx)
+
+	stepMap := make(map[string]Step)
+	for _, rg := range p.ResourceGroups {
+		for _, step := range rg.Steps {
+			stepMap[step.StepName()] = step
+		}
+	}
+
+	for _, name := range steps {
+		step, ok := stepMap[name]
+		if !ok {
+			return fmt.Errorf("step %q not found", name)
+		}
+		for _, dep := range step.Dependencies() {
+			if !slices.Contains(steps, dep) {
+				logger.Info("dependency of selected step will not run", "step", name, "dependency", dep)
+			}
+		}
+	}
+	return nil
+}
+
 func RunResourceGroup(rg *ResourceGroup, ctx context.Context, options *PipelineRunOptions, executionTarget ExecutionTarget, outputs map[string]output) error {
 	logger := logr.FromContextOrDiscard(ctx)
 
@@ -161,8 +197,8 @@ func RunResourceGroup(rg *ResourceGroup, ctx context.Context, options *PipelineR
 }
 
 func RunStep(s Step, ctx context.Context, kubeconfigFile string, executionTarget ExecutionTarget, options *PipelineRunOptions, outPuts map[string]output) (output, error) {
-	if options.Step != "" && s.StepName() != options.Step {
-		// skip steps that don't match the specified step name
+	if len(options.Steps) > 0 && !slices.Contains(options.Steps, s.StepName()) {
+		// skip steps that don't match the selected step names
 		return nil, nil
 	}
 	fmt.Println("\n---------------------")
@@ -2,8 +2,11 @@ package pipeline
 
 import (
 	"context"
+	"strings"
 	"testing"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
 	"gotest.tools/v3/assert"
 )
 
@@ -140,3 +143,46 @@ func TestAddInputVars(t *testing.T) {
 	}, mapOutput)
 	assert.Error(t, err, "step foobar not found in provided outputs")
 }
+
+func TestPipelineRunOnlySteps(t *testing.T) {
+	ranSteps := []string{}
+	outputFunc := func(name string) func(string) {
+		return func(string) {
+			ranSteps = append(ranSteps, name)
+		}
+	}
+	pipeline := &Pipeline{
+		ResourceGroups: []*ResourceGroup{
+			{
+				Name:         "test",
+				Subscription: "test",
+				Steps: []Step{
+					NewShellStep("step1", "echo hello").WithOutputFunc(outputFunc("step1")),
+					NewShellStep("step2", "echo hello").WithOutputFunc(outputFunc("step2")).WithDependsOn("step1"),
+					NewShellStep("step3", "echo hello").WithOutputFunc(outputFunc("step3")),
+				},
+			},
+		},
+	}
+	options := &PipelineRunOptions{
+		Steps: []string{"step2", "step3"},
+		SubsciptionLookupFunc: func(_ context.Context, _ string) (string, error) {
+			return "test", nil
+		},
+	}
+
+	var logged []string
+	logger := funcr.New(func(prefix, args string) {
+		logged = append(logged, args)
+	}, funcr.Options{})
+
+	err := RunPipeline(pipeline, logr.NewContext(context.Background(), logger), options)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ranSteps, []string{"step2", "step3"})
+	assert.Equal(t, len(logged), 1)
+	assert.Assert(t, strings.Contains(logged[0], `"dependency"="step1"`), logged[0])
+
+	options.Steps = []string{"step4"}
+	err = RunPipeline(pipeline, context.Background(), options)
+	assert.Error(t, err, `step "step4" not found`)
+}